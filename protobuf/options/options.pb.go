@@ -0,0 +1,177 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.26.0
+// 	protoc        v3.21.12
+// source: protobuf/options/options.proto
+
+// Options annotating the TestBert services
+
+package options
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// How calls to a method are authenticated
+type AuthOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Callable without a token, the handler is responsible for any checks
+	Public bool `protobuf:"varint,1,opt,name=public,proto3" json:"public,omitempty"`
+}
+
+func (x *AuthOptions) Reset() {
+	*x = AuthOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protobuf_options_options_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuthOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthOptions) ProtoMessage() {}
+
+func (x *AuthOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_options_options_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthOptions.ProtoReflect.Descriptor instead.
+func (*AuthOptions) Descriptor() ([]byte, []int) {
+	return file_protobuf_options_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AuthOptions) GetPublic() bool {
+	if x != nil {
+		return x.Public
+	}
+	return false
+}
+
+var file_protobuf_options_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*AuthOptions)(nil),
+		Field:         50013,
+		Name:          "testbert.auth",
+		Tag:           "bytes,50013,opt,name=auth",
+		Filename:      "protobuf/options/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional testbert.AuthOptions auth = 50013;
+	E_Auth = &file_protobuf_options_options_proto_extTypes[0]
+)
+
+var File_protobuf_options_options_proto protoreflect.FileDescriptor
+
+var file_protobuf_options_options_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x6f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x2f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x08, 0x74, 0x65, 0x73, 0x74, 0x62, 0x65, 0x72, 0x74, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x65, 0x73, 0x63,
+	0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x25, 0x0a, 0x0b,
+	0x41, 0x75, 0x74, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x70,
+	0x75, 0x62, 0x6c, 0x69, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x75, 0x62,
+	0x6c, 0x69, 0x63, 0x3a, 0x4b, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x12, 0x1e, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65,
+	0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xdd, 0x86, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x62, 0x65, 0x72, 0x74, 0x2e, 0x41,
+	0x75, 0x74, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68,
+	0x42, 0x1b, 0x5a, 0x19, 0x74, 0x65, 0x73, 0x74, 0x62, 0x65, 0x72, 0x74, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_protobuf_options_options_proto_rawDescOnce sync.Once
+	file_protobuf_options_options_proto_rawDescData = file_protobuf_options_options_proto_rawDesc
+)
+
+func file_protobuf_options_options_proto_rawDescGZIP() []byte {
+	file_protobuf_options_options_proto_rawDescOnce.Do(func() {
+		file_protobuf_options_options_proto_rawDescData = protoimpl.X.CompressGZIP(file_protobuf_options_options_proto_rawDescData)
+	})
+	return file_protobuf_options_options_proto_rawDescData
+}
+
+var file_protobuf_options_options_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_protobuf_options_options_proto_goTypes = []interface{}{
+	(*AuthOptions)(nil),                // 0: testbert.AuthOptions
+	(*descriptorpb.MethodOptions)(nil), // 1: google.protobuf.MethodOptions
+}
+var file_protobuf_options_options_proto_depIdxs = []int32{
+	1, // 0: testbert.auth:extendee -> google.protobuf.MethodOptions
+	0, // 1: testbert.auth:type_name -> testbert.AuthOptions
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	1, // [1:2] is the sub-list for extension type_name
+	0, // [0:1] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_protobuf_options_options_proto_init() }
+func file_protobuf_options_options_proto_init() {
+	if File_protobuf_options_options_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_protobuf_options_options_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuthOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_protobuf_options_options_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_protobuf_options_options_proto_goTypes,
+		DependencyIndexes: file_protobuf_options_options_proto_depIdxs,
+		MessageInfos:      file_protobuf_options_options_proto_msgTypes,
+		ExtensionInfos:    file_protobuf_options_options_proto_extTypes,
+	}.Build()
+	File_protobuf_options_options_proto = out.File
+	file_protobuf_options_options_proto_rawDesc = nil
+	file_protobuf_options_options_proto_goTypes = nil
+	file_protobuf_options_options_proto_depIdxs = nil
+}
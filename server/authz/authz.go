@@ -0,0 +1,89 @@
+// Package authz Decides what callers are allowed to do with collections, groups and API keys
+package authz
+
+import (
+	"context"
+
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+)
+
+// Action Something a caller wants to do with a resource
+type Action string
+
+const (
+	ActionView  Action = "view"
+	ActionEdit  Action = "edit"
+	ActionShare Action = "share"
+	// ActionManage Managing grants, reading the audit log and transferring ownership
+	ActionManage Action = "manage"
+	// ActionRevokeToken Revoking [Resource.Token]
+	ActionRevokeToken Action = "revoke_token"
+	// ActionViewGroup Listing the members of [Resource.Group]
+	ActionViewGroup Action = "view_group"
+	// ActionManageGroup Deleting or changing the members of [Resource.Group]
+	ActionManageGroup Action = "manage_group"
+	// ActionManageAPIKey Creating, listing or revoking API keys in the org of [Resource.APIKey]
+	ActionManageAPIKey Action = "manage_api_key"
+)
+
+// Decision The outcome of evaluating a policy
+type Decision int
+
+const (
+	// Hide Refuse without revealing that the resource exists. The zero value so policies fail closed
+	Hide Decision = iota
+	// Forbid Refuse a caller who is allowed to know the resource exists
+	Forbid
+	Allow
+)
+
+// Err The error to return for d, hidden when the resource should not be revealed
+func (d Decision) Err(hidden error) error {
+	switch d {
+	case Allow:
+		return nil
+	case Forbid:
+		return tberrors.ErrUnauthorized
+	default:
+		return hidden
+	}
+}
+
+// Subject Who a decision is made for
+type Subject struct {
+	UserID uuid.UUID
+	OrgID  uuid.UUID
+	// OrgAdmin Whether the subject has the org admin role in OrgID
+	OrgAdmin bool
+}
+
+// NewSubject The logged in user making the request in ctx
+func NewSubject(ctx context.Context, user, org *uuid.UUID) *Subject {
+	return &Subject{
+		UserID:   *user,
+		OrgID:    *org,
+		OrgAdmin: model.IsOrgAdmin(ctx),
+	}
+}
+
+// Resource What a decision is about. Collection decisions are given the subject's effective grant on the
+// collection, combining their own with those of their groups, which is nil when they have none
+type Resource struct {
+	Collection *model.Collection
+	Grant      *model.Grant
+	// Token The token being revoked, for [ActionRevokeToken]
+	Token *model.SharingToken
+	Group *model.Group
+	// APIKey The key being revoked, or one standing for the org keys are created in or listed for
+	APIKey *model.APIKey
+}
+
+// Policy Decides whether a subject may take an action on a resource. Policies are evaluated by the server,
+// within the datastore's transaction for changes to a collection. Lists only ask about collections the subject
+// owns, that are in their org or that they have a grant on
+type Policy interface {
+	Decide(s *Subject, a Action, r *Resource) Decision
+}
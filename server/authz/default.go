@@ -0,0 +1,84 @@
+package authz
+
+// Default The built in rules. Owners can do anything with their collections, members of the collection's org
+// get what its org flags allow, grants add to that and org admins can do anything in their org, including
+// managing its API keys
+type Default struct{}
+
+// Decide implements [Policy].
+func (Default) Decide(s *Subject, a Action, r *Resource) Decision {
+	switch a {
+	case ActionView, ActionEdit, ActionShare, ActionManage:
+		return decideCollection(s, a, r)
+	case ActionRevokeToken:
+		// Whoever can share the collection can revoke its tokens, without needing to view it
+		if shareable(s, r) {
+			return Allow
+		}
+		return Hide
+	case ActionViewGroup:
+		if r.Group.OrgID != s.OrgID {
+			return Hide
+		}
+		return Allow
+	case ActionManageGroup:
+		if r.Group.OrgID != s.OrgID {
+			return Hide
+		}
+		if r.Group.CreatedBy == s.UserID || s.OrgAdmin {
+			return Allow
+		}
+		return Forbid
+	case ActionManageAPIKey:
+		if r.APIKey.OrgID != s.OrgID {
+			return Hide
+		}
+		if s.OrgAdmin {
+			return Allow
+		}
+		return Forbid
+	}
+
+	return Hide
+}
+
+// decideCollection applies the collection rules, hiding collections the subject can't view unless they are
+// allowed to change them anyway
+func decideCollection(s *Subject, a Action, r *Resource) Decision {
+	c, g := r.Collection, r.Grant
+	owner := c.UserID == s.UserID
+	inOrg := c.OrgID == s.OrgID
+	admin := inOrg && s.OrgAdmin
+
+	// Every grant allows viewing. The org flags are independent, org_edit works without org_view but sharing
+	// and managing need the collection to be visible
+	viewable := owner || admin || (inOrg && c.OrgView) || g != nil
+
+	allowed := false
+	switch a {
+	case ActionView:
+		allowed = viewable
+	case ActionEdit:
+		allowed = owner || admin || (inOrg && c.OrgEdit) || (g != nil && g.CanEdit)
+	case ActionShare:
+		allowed = viewable && shareable(s, r)
+	case ActionManage:
+		allowed = owner || admin
+	}
+
+	switch {
+	case allowed:
+		return Allow
+	case viewable:
+		return Forbid
+	default:
+		return Hide
+	}
+}
+
+// shareable Whether the subject may share the collection, or revoke its tokens, ignoring whether they can view it
+func shareable(s *Subject, r *Resource) bool {
+	c, g := r.Collection, r.Grant
+	inOrg := c.OrgID == s.OrgID
+	return c.UserID == s.UserID || (inOrg && (c.OrgShare || s.OrgAdmin)) || (g != nil && g.CanShare)
+}
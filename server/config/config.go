@@ -4,40 +4,128 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Configuration struct {
-	ServerPort      string
-	AuthSecret      string
-	DBHost          string
-	DBPort          string
-	DBUser          string
-	DBPassword      string
-	DBName          string
-	OtlpEndpoint    string
-	OtlpPort        string
-	OtelServiceName string
-	OtelEnvironment string
+	ServerPort string
+	AuthSecret string
+	// APIKeySecret Key API keys are hashed with before being stored, separate from ShareTokenSecret so a hash of
+	// one can't pass as the other
+	APIKeySecret string
+	// ShareTokenSecret Key sharing tokens are hashed with before being stored
+	ShareTokenSecret string
+	DBHost           string
+	DBPort           string
+	DBUser           string
+	DBPassword       string
+	DBName           string
+	OtlpEndpoint     string
+	OtlpPort         string
+	OtelServiceName  string
+	OtelEnvironment  string
+	// AuthJWKS File path or http(s) URL of the JSON Web Key Set tokens are verified with, instead of AuthSecret
+	AuthJWKS string
+	// AuthJWKSRefresh How often the JWKS is reloaded to pick up rotated keys
+	AuthJWKSRefresh time.Duration
+	// AuthAlgorithms Signing algorithms tokens are accepted with
+	AuthAlgorithms []string
+	// AuthIssuer iss claim tokens must have, required with AuthJWKS and any issuer is accepted when empty
+	// otherwise
+	AuthIssuer string
+	// AuthAudience aud claim tokens must include, any audience is accepted when empty
+	AuthAudience string
+	// AuthLeeway Clock skew allowed when checking a token's exp, nbf and iat claims
+	AuthLeeway time.Duration
+	// TraceRedactKeys Span attributes that carry secrets and are exported as fingerprints
+	TraceRedactKeys []string
+	// EventSink Where access events are published: none, file, webhook or queue
+	EventSink string
+	// EventFile Newline delimited JSON file events are appended to by the file sink
+	EventFile string
+	// EventWebhookURL Endpoint the webhook sink POSTs each event to
+	EventWebhookURL string
+	// EventWebhookRetries Times a failed webhook delivery is retried
+	EventWebhookRetries int
+	// EventQueueAddr host:port of the NATS compatible server used by the queue sink
+	EventQueueAddr string
+	// EventQueueSubject Subject events are published on by the queue sink
+	EventQueueSubject string
+	// EventBufferPolicy What happens to events for reads when the buffer in front of the publisher is full:
+	// block, drop-oldest, drop-newest or spill
+	EventBufferPolicy string
+	// EventBufferSize Number of events held in memory waiting for the publisher
+	EventBufferSize int
+	// EventBufferTimeout How long the block policy waits for room before dropping an event
+	EventBufferTimeout time.Duration
+	// EventSpillFile Where the spill policy writes events that don't fit in the buffer
+	EventSpillFile string
+	// EventRelayInterval How often the outbox is checked for events to publish
+	EventRelayInterval time.Duration
+	// EventOutboxRetention How long sent events stay in the outbox, the audit log keeps them regardless
+	EventOutboxRetention time.Duration
+	// TrashRetention How long deleted collections stay in the trash before being purged
+	TrashRetention time.Duration
+	// TrashPurgeInterval How often the trash and the outbox are checked for collections and sent events to purge
+	TrashPurgeInterval time.Duration
 }
 
 func NewConfig() *Configuration {
 	cfg := &Configuration{
-		ServerPort:      os.Getenv("TESTBERT_SERVER_PORT"),
-		AuthSecret:      os.Getenv("TESTBERT_AUTH_SECRET"),
-		DBHost:          os.Getenv("TESTBERT_DB_HOST"),
-		DBPort:          os.Getenv("TESTBERT_DB_PORT"),
-		DBUser:          os.Getenv("TESTBERT_DB_USER"),
-		DBPassword:      os.Getenv("TESTBERT_DB_PASSWORD"),
-		DBName:          os.Getenv("TESTBERT_DB_NAME"),
-		OtlpEndpoint:    os.Getenv("TESTBERT_OTLP_ENDPOINT"),
-		OtlpPort:        os.Getenv("TESTBERT_OTLP_PORT"),
-		OtelServiceName: os.Getenv("TESTBERT_OTEL_SERVICE_NAME"),
-		OtelEnvironment: os.Getenv("TESTBERT_OTEL_ENVIRONMENT"),
+		ServerPort:           os.Getenv("TESTBERT_SERVER_PORT"),
+		AuthSecret:           os.Getenv("TESTBERT_AUTH_SECRET"),
+		AuthJWKS:             os.Getenv("TESTBERT_AUTH_JWKS"),
+		AuthJWKSRefresh:      durationFromEnv("TESTBERT_AUTH_JWKS_REFRESH", 5*time.Minute),
+		AuthIssuer:           os.Getenv("TESTBERT_AUTH_ISSUER"),
+		AuthAudience:         os.Getenv("TESTBERT_AUTH_AUDIENCE"),
+		AuthLeeway:           durationFromEnv("TESTBERT_AUTH_LEEWAY", 30*time.Second),
+		ShareTokenSecret:     os.Getenv("TESTBERT_SHARE_TOKEN_SECRET"),
+		APIKeySecret:         os.Getenv("TESTBERT_API_KEY_SECRET"),
+		DBHost:               os.Getenv("TESTBERT_DB_HOST"),
+		DBPort:               os.Getenv("TESTBERT_DB_PORT"),
+		DBUser:               os.Getenv("TESTBERT_DB_USER"),
+		DBPassword:           os.Getenv("TESTBERT_DB_PASSWORD"),
+		DBName:               os.Getenv("TESTBERT_DB_NAME"),
+		OtlpEndpoint:         os.Getenv("TESTBERT_OTLP_ENDPOINT"),
+		OtlpPort:             os.Getenv("TESTBERT_OTLP_PORT"),
+		OtelServiceName:      os.Getenv("TESTBERT_OTEL_SERVICE_NAME"),
+		OtelEnvironment:      os.Getenv("TESTBERT_OTEL_ENVIRONMENT"),
+		TraceRedactKeys:      listFromEnv("TESTBERT_TRACE_REDACT_KEYS", []string{"share.token"}),
+		EventSink:            os.Getenv("TESTBERT_EVENT_SINK"),
+		EventFile:            os.Getenv("TESTBERT_EVENT_FILE"),
+		EventWebhookURL:      os.Getenv("TESTBERT_EVENT_WEBHOOK_URL"),
+		EventWebhookRetries:  intFromEnv("TESTBERT_EVENT_WEBHOOK_RETRIES", 3),
+		EventQueueAddr:       os.Getenv("TESTBERT_EVENT_QUEUE_ADDR"),
+		EventQueueSubject:    os.Getenv("TESTBERT_EVENT_QUEUE_SUBJECT"),
+		EventRelayInterval:   durationFromEnv("TESTBERT_EVENT_RELAY_INTERVAL", time.Second),
+		EventOutboxRetention: durationFromEnv("TESTBERT_EVENT_OUTBOX_RETENTION", 7*24*time.Hour),
+		EventBufferPolicy:    os.Getenv("TESTBERT_EVENT_BUFFER_POLICY"),
+		EventBufferSize:      intFromEnv("TESTBERT_EVENT_BUFFER_SIZE", 1000),
+		EventBufferTimeout:   durationFromEnv("TESTBERT_EVENT_BUFFER_TIMEOUT", 250*time.Millisecond),
+		EventSpillFile:       os.Getenv("TESTBERT_EVENT_SPILL_FILE"),
+		TrashRetention:       durationFromEnv("TESTBERT_TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeInterval:   durationFromEnv("TESTBERT_TRASH_PURGE_INTERVAL", time.Hour),
 	}
 
-	if cfg.AuthSecret == "" {
+	if cfg.AuthSecret == "" && cfg.AuthJWKS == "" {
 		log.Fatal("TESTBERT_AUTH_SECRET not set")
 	}
+	if cfg.AuthJWKS != "" && cfg.AuthIssuer == "" {
+		log.Fatal("TESTBERT_AUTH_ISSUER not set")
+	}
+	if cfg.AuthJWKS != "" {
+		cfg.AuthAlgorithms = listFromEnv("TESTBERT_AUTH_ALGORITHMS", []string{"RS256", "ES256"})
+	} else {
+		cfg.AuthAlgorithms = listFromEnv("TESTBERT_AUTH_ALGORITHMS", []string{"HS256"})
+	}
+	if cfg.ShareTokenSecret == "" {
+		log.Fatal("TESTBERT_SHARE_TOKEN_SECRET not set")
+	}
+	if cfg.APIKeySecret == "" {
+		log.Fatal("TESTBERT_API_KEY_SECRET not set")
+	}
 	if cfg.DBPassword == "" {
 		log.Fatal("TESTBERT_DB_PASSWORD not set")
 	}
@@ -68,6 +156,61 @@ func NewConfig() *Configuration {
 	if cfg.OtelEnvironment == "" {
 		cfg.OtelEnvironment = "development"
 	}
+	if cfg.EventSink == "" {
+		cfg.EventSink = "none"
+	}
+	if cfg.EventFile == "" {
+		cfg.EventFile = "events.ndjson"
+	}
+	if cfg.EventQueueAddr == "" {
+		cfg.EventQueueAddr = "localhost:4222"
+	}
+	if cfg.EventQueueSubject == "" {
+		cfg.EventQueueSubject = "testbert.access"
+	}
+	if cfg.EventBufferPolicy == "" {
+		cfg.EventBufferPolicy = "block"
+	}
+	if cfg.EventSpillFile == "" {
+		cfg.EventSpillFile = "events-spill.ndjson"
+	}
 
 	return cfg
 }
+
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Fatalf("%s is not a valid duration: %q", key, value)
+	}
+
+	return d
+}
+
+func listFromEnv(key string, def []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	return strings.Split(value, ",")
+}
+
+func intFromEnv(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		log.Fatalf("%s is not a valid count: %q", key, value)
+	}
+
+	return i
+}
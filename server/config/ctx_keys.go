@@ -5,4 +5,12 @@ type CtxKey string
 const (
 	KeyUserID = CtxKey("user_id")
 	KeyOrgID  = CtxKey("org_id")
+	// KeyRole The role claim of the logged in user, empty when they have none
+	KeyRole = CtxKey("role")
+	// KeyServicePrincipal Whether the caller is a service principal authenticated with an API key, whose user
+	// id is the key's id
+	KeyServicePrincipal = CtxKey("service_principal")
 )
+
+// RoleOrgAdmin Role of users who can manage every collection and group in their org
+const RoleOrgAdmin = "org_admin"
@@ -0,0 +1,23 @@
+package datastore
+
+import (
+	"context"
+
+	"testbert/server/model"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyStore interface {
+	// CreateAPIKey creates a key for the user's org, only the returned key carries the raw secret
+	CreateAPIKey(ctx context.Context, name string, user, org *uuid.UUID) (*model.APIKey, error)
+	// ListAPIKeys returns every key in the org, oldest first, without the raw secret
+	ListAPIKeys(ctx context.Context, org *uuid.UUID) ([]*model.APIKey, error)
+	// GetAPIKey returns a key in any org
+	GetAPIKey(ctx context.Context, id *uuid.UUID) (*model.APIKey, error)
+	// UseAPIKey looks up a key by its raw secret and records that it was used, at most once every
+	// [model.APIKeyUseInterval]
+	UseAPIKey(ctx context.Context, key string) (*model.APIKey, error)
+	// DeleteAPIKey revokes a key, it can't be used from then on
+	DeleteAPIKey(ctx context.Context, id *uuid.UUID) error
+}
@@ -0,0 +1,19 @@
+package datastore
+
+import (
+	"context"
+
+	"testbert/server/model"
+
+	"github.com/google/uuid"
+)
+
+// AuditStore Every access event is kept in the audit log, changes are added by the store in the same
+// transaction as the change itself
+type AuditStore interface {
+	// RecordAccess adds an event for an access that did not change anything, such as a read, to the audit log
+	RecordAccess(ctx context.Context, e *model.AccessEvent) error
+	// ListAuditEvents returns up to limit events for a collection, newest first, starting before the given
+	// event id when it is not zero. Collections in the trash can still be audited
+	ListAuditEvents(ctx context.Context, collectionID *uuid.UUID, filter *model.AuditFilter, before int64, limit int, user, org *uuid.UUID) ([]*model.AccessEvent, error)
+}
@@ -1,15 +1,27 @@
 package datastore
 
 import (
+	"context"
+
 	"testbert/server/model"
 
 	"github.com/google/uuid"
 )
 
 type CollectionStore interface {
-	CreateCollection(c *model.Collection, user, org *uuid.UUID) (*model.Collection, error)
-	GetCollection(id, user, org *uuid.UUID) (*model.Collection, error)
-	GetCollectionFromSharingToken(token string) (*model.Collection, error)
-	UpdateCollection(c *model.Collection, user, org *uuid.UUID) (*model.Collection, error)
-	DeleteCollection(id, user, org *uuid.UUID) error
+	CreateCollection(ctx context.Context, c *model.Collection, user, org *uuid.UUID) (*model.Collection, error)
+	// GetCollection returns a collection that is not in the trash without checking access
+	GetCollection(ctx context.Context, id *uuid.UUID) (*model.Collection, error)
+	// GetCollectionFromSharingToken counts a use of the token. It fails with token not found if the token is
+	// unknown, has expired, has no uses left or its collection is in the trash
+	GetCollectionFromSharingToken(ctx context.Context, token string) (*model.Collection, error)
+	// UpdateCollection only changes the given fields, or every field when none are given. It fails with an etag
+	// mismatch when c.Version is not zero and does not match the stored version
+	UpdateCollection(ctx context.Context, c *model.Collection, fields []model.CollectionField, user, org *uuid.UUID, check CollectionCheck) (*model.Collection, error)
+	// DeleteCollection moves the collection to the trash. It fails with an etag mismatch when version is not zero and does not match the
+	// stored version
+	DeleteCollection(ctx context.Context, id *uuid.UUID, version int64, user, org *uuid.UUID, check CollectionCheck) error
+	// ListCollections returns up to limit collections related to the user that match the filter ordered by
+	// id, starting after the given id when it is not nil. The caller decides which the user can view
+	ListCollections(ctx context.Context, filter model.CollectionFilter, after *uuid.UUID, limit int, user, org *uuid.UUID) ([]*model.Collection, error)
 }
@@ -1,8 +1,26 @@
 // Package datastore Interface for datastore interaction
 package datastore
 
-// TestBertDatastore Implementation is responsible for enforcing auth logic
+import "testbert/server/model"
+
+// TestBertDatastore Implementations authorize changes to a collection with the [CollectionCheck] they are
+// given, and trust callers to have authorized other requests with an [authz.Policy] first, looking up what
+// the policy needs without checking access. Collection lists only return those the user is related to, by
+// owning them, being in their org or having a grant on them, for the caller to filter with the policy
 type TestBertDatastore interface {
 	CollectionStore
 	SharingTokenStore
+	RevisionStore
+	TrashStore
+	OutboxStore
+	AuditStore
+	GrantStore
+	GroupStore
+	TransferStore
+	APIKeyStore
 }
+
+// CollectionCheck Authorizes a change to a collection given the user's effective grant on it, which is nil
+// when they have none. Stores call it with the collection locked in the same transaction as the change, so
+// access can't change before the change is made
+type CollectionCheck func(c *model.Collection, g *model.Grant) error
@@ -0,0 +1,26 @@
+package datastore
+
+import (
+	"context"
+
+	"testbert/server/model"
+
+	"github.com/google/uuid"
+)
+
+type GrantStore interface {
+	// GrantAccess gives g.UserID, or every member of g.GroupID when it is set, access to g.CollectionID if
+	// check allows it, replacing any grant they already have. Groups must be in the user's org
+	GrantAccess(ctx context.Context, g *model.Grant, user, org *uuid.UUID, check CollectionCheck) (*model.Grant, error)
+	// RevokeAccess removes the grant on g.CollectionID held by g.UserID, or g.GroupID when it is set, if check
+	// allows it
+	RevokeAccess(ctx context.Context, g *model.Grant, user, org *uuid.UUID, check CollectionCheck) error
+	// ListGrants returns every user and group grant on a collection, oldest first
+	ListGrants(ctx context.Context, collectionID, user, org *uuid.UUID) ([]*model.Grant, error)
+	// GetEffectiveGrant combines the user's grant on a collection with those of their groups, returning nil
+	// when they have none
+	GetEffectiveGrant(ctx context.Context, collectionID, user *uuid.UUID) (*model.Grant, error)
+	// GetEffectiveGrants is GetEffectiveGrant for several collections, keyed by collection and leaving out
+	// those the user has no grant on
+	GetEffectiveGrants(ctx context.Context, collectionIDs []uuid.UUID, user *uuid.UUID) (map[uuid.UUID]*model.Grant, error)
+}
@@ -0,0 +1,25 @@
+package datastore
+
+import (
+	"context"
+
+	"testbert/server/model"
+
+	"github.com/google/uuid"
+)
+
+type GroupStore interface {
+	// CreateGroup creates a group in the user's org, names are unique within an org
+	CreateGroup(ctx context.Context, name string, user, org *uuid.UUID) (*model.Group, error)
+	// ListGroups returns every group in the user's org ordered by name
+	ListGroups(ctx context.Context, user, org *uuid.UUID) ([]*model.Group, error)
+	// GetGroup returns a group in any org
+	GetGroup(ctx context.Context, id *uuid.UUID) (*model.Group, error)
+	// DeleteGroup deletes a group along with its memberships and grants
+	DeleteGroup(ctx context.Context, id, user, org *uuid.UUID) error
+	// AddGroupMember adds member to a group, adding an existing member does nothing
+	AddGroupMember(ctx context.Context, id, member, user, org *uuid.UUID) (*model.GroupMember, error)
+	RemoveGroupMember(ctx context.Context, id, member, user, org *uuid.UUID) error
+	// ListGroupMembers returns the members of a group, oldest first
+	ListGroupMembers(ctx context.Context, id, user, org *uuid.UUID) ([]*model.GroupMember, error)
+}
@@ -0,0 +1,121 @@
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"time"
+
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+)
+
+// CreateAPIKey implements [datastore.APIKeyStore].
+func (m *memStore) CreateAPIKey(ctx context.Context, name string, user, org *uuid.UUID) (*model.APIKey, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := model.NewAPIKey()
+	k := &model.APIKey{
+		ID:        uuid.New(),
+		OrgID:     *org,
+		Name:      name,
+		KeyHash:   m.apiKeyHasher.Hash(key),
+		CreatedBy: *user,
+		CreatedAt: time.Now().UTC(),
+	}
+	m.apiKeys[k.KeyHash] = k
+
+	out := *k
+	out.Key = key
+	out.KeyHash = ""
+	return &out, nil
+}
+
+// ListAPIKeys implements [datastore.APIKeyStore].
+func (m *memStore) ListAPIKeys(ctx context.Context, org *uuid.UUID) ([]*model.APIKey, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	out := []*model.APIKey{}
+	for _, k := range m.apiKeys {
+		if k.OrgID != *org {
+			continue
+		}
+
+		copied := *k
+		copied.KeyHash = ""
+		out = append(out, &copied)
+	}
+
+	slices.SortFunc(out, func(a, b *model.APIKey) int {
+		if n := a.CreatedAt.Compare(b.CreatedAt); n != 0 {
+			return n
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	return out, nil
+}
+
+// GetAPIKey implements [datastore.APIKeyStore].
+func (m *memStore) GetAPIKey(ctx context.Context, id *uuid.UUID) (*model.APIKey, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	k, ok := m.apiKeyByID(id)
+	if !ok {
+		return nil, tberrors.ErrAPIKeyNotFound
+	}
+
+	out := *k
+	out.KeyHash = ""
+	return &out, nil
+}
+
+// UseAPIKey implements [datastore.APIKeyStore].
+func (m *memStore) UseAPIKey(ctx context.Context, key string) (*model.APIKey, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	k, ok := m.apiKeys[m.apiKeyHasher.Hash(key)]
+	if !ok {
+		return nil, tberrors.ErrAPIKeyNotFound
+	}
+
+	now := time.Now().UTC()
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= model.APIKeyUseInterval {
+		k.LastUsedAt = &now
+	}
+
+	out := *k
+	out.KeyHash = ""
+	return &out, nil
+}
+
+// DeleteAPIKey implements [datastore.APIKeyStore].
+func (m *memStore) DeleteAPIKey(ctx context.Context, id *uuid.UUID) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	k, ok := m.apiKeyByID(id)
+	if !ok {
+		return tberrors.ErrAPIKeyNotFound
+	}
+
+	delete(m.apiKeys, k.KeyHash)
+	return nil
+}
+
+// apiKeyByID looks up a key by its id rather than its hash, the lock must be held
+func (m *memStore) apiKeyByID(id *uuid.UUID) (*model.APIKey, bool) {
+	for _, k := range m.apiKeys {
+		if k.ID == *id {
+			return k, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,48 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+)
+
+// RecordAccess implements [datastore.AuditStore].
+func (m *memStore) RecordAccess(ctx context.Context, e *model.AccessEvent) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.recordAudit(e)
+	return nil
+}
+
+// ListAuditEvents implements [datastore.AuditStore].
+func (m *memStore) ListAuditEvents(ctx context.Context, collectionID *uuid.UUID, filter *model.AuditFilter, before int64, limit int, user, org *uuid.UUID) ([]*model.AccessEvent, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// Trashed collections are included so their history can be reviewed before they are restored or purged
+	if _, ok := m.collections[*collectionID]; !ok {
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	id := collectionID.String()
+	out := []*model.AccessEvent{}
+	for i := len(m.audit) - 1; i >= 0 && len(out) < limit; i-- {
+		e := m.audit[i]
+		switch {
+		case e.CollectionID != id:
+		case before != 0 && e.ID >= before:
+		case len(filter.Actions) > 0 && !slices.Contains(filter.Actions, e.Action):
+		case !filter.Since.IsZero() && e.OccurredAt.Before(filter.Since):
+		case !filter.Until.IsZero() && !e.OccurredAt.Before(filter.Until):
+		default:
+			out = append(out, e)
+		}
+	}
+
+	return out, nil
+}
@@ -1,105 +1,226 @@
 package memstore
 
 import (
+	"bytes"
+	"context"
+	"slices"
+	"time"
+
+	"testbert/server/datastore"
 	"testbert/server/model"
+	"testbert/server/sharetoken"
 	"testbert/server/tberrors"
 
 	"github.com/google/uuid"
 )
 
 // CreateCollection implements [datastore.CollectionStore].
-func (m *memStore) CreateCollection(c *model.Collection, user, org *uuid.UUID) (*model.Collection, error) {
+func (m *memStore) CreateCollection(ctx context.Context, c *model.Collection, user, org *uuid.UUID) (*model.Collection, error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
+	now := time.Now().UTC()
+
 	c.ID = uuid.New()
 	c.UserID = *user
 	c.OrgID = *org
+	c.DataSize = int64(len(c.Data))
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	c.CreatedBy = *user
+	c.LastModifiedBy = *user
+	c.Version = 1
 	m.collections[c.ID] = c
+	m.revisions[c.ID] = []*model.Revision{model.NewRevision(c, *org)}
+	m.recordEvent(model.NewAccessEvent(ctx, model.ActionCreate, c.ID.String(), user, org))
 	return c, nil
 }
 
 // DeleteCollection implements [datastore.CollectionStore].
-func (m *memStore) DeleteCollection(id, user, org *uuid.UUID) error {
+func (m *memStore) DeleteCollection(ctx context.Context, id *uuid.UUID, version int64, user, org *uuid.UUID, check datastore.CollectionCheck) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	c, ok := m.collections[*id]
-	if !ok {
-		return tberrors.ErrCollectionNotFound
+	c, err := m.checkCollection(id, user, false, check)
+	if err != nil {
+		return err
 	}
 
-	if c.UserID != *user {
-		if !c.OrgEdit || c.OrgID != *org {
-			return tberrors.ErrUnauthorized
-		}
+	if version != 0 && c.Version != version {
+		return tberrors.ErrEtagMismatch
 	}
 
-	delete(m.collections, *id)
+	now := time.Now().UTC()
+	deletedBy := *user
 
-	// Cascade delete
-	for k, t := range m.sharingTokens {
-		if t.CollectionID == *id {
-			delete(m.sharingTokens, k)
-		}
-	}
+	out := *c
+	out.DeletedAt = &now
+	out.DeletedBy = &deletedBy
+	out.Version = c.Version + 1
+
+	m.collections[out.ID] = &out
+	m.recordEvent(model.NewAccessEvent(ctx, model.ActionDelete, out.ID.String(), user, org))
 	return nil
 }
 
 // GetCollection implements [datastore.CollectionStore].
-func (m *memStore) GetCollection(id, user, org *uuid.UUID) (*model.Collection, error) {
+func (m *memStore) GetCollection(ctx context.Context, id *uuid.UUID) (*model.Collection, error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	c, ok := m.collections[*id]
+	c, ok := m.liveCollection(id)
 	if !ok {
 		return nil, tberrors.ErrCollectionNotFound
 	}
 
-	if c.UserID != *user {
-		if !c.OrgView || c.OrgID != *org {
-			return nil, tberrors.ErrUnauthorized
-		}
-	}
-
 	return c, nil
 }
 
 // GetCollectionFromSharingToken implements [datastore.CollectionStore].
-func (m *memStore) GetCollectionFromSharingToken(token string) (*model.Collection, error) {
+func (m *memStore) GetCollectionFromSharingToken(ctx context.Context, token string) (*model.Collection, error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	t, ok := m.sharingTokens[token]
+	t, ok := m.sharingTokens[m.hasher.Hash(token)]
 	if !ok {
 		return nil, tberrors.ErrTokenNotFound
 	}
-	c, ok := m.collections[t.CollectionID]
+	c, ok := m.liveCollection(&t.CollectionID)
 	if !ok {
-		return nil, tberrors.ErrCollectionNotFound
+		return nil, tberrors.ErrTokenNotFound
 	}
+	now := time.Now().UTC()
+	if !t.Usable(now) {
+		return nil, tberrors.ErrTokenNotFound
+	}
+	t.UseCount++
+	t.LastAccessedAt = &now
+
+	e := model.NewAccessEvent(ctx, model.ActionReadShared, c.ID.String(), nil, nil)
+	e.TokenFingerprint = sharetoken.FingerprintFromHash(t.TokenHash)
+	m.recordEvent(e)
 	return c, nil
 }
 
 // UpdateCollection implements [datastore.CollectionStore].
-func (m *memStore) UpdateCollection(c *model.Collection, user, org *uuid.UUID) (*model.Collection, error) {
+func (m *memStore) UpdateCollection(ctx context.Context, c *model.Collection, fields []model.CollectionField, user, org *uuid.UUID, check datastore.CollectionCheck) (*model.Collection, error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	existing, ok := m.collections[c.ID]
-	if !ok {
-		return nil, tberrors.ErrCollectionNotFound
+	existing, err := m.checkCollection(&c.ID, user, false, check)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Version != 0 && c.Version != existing.Version {
+		return nil, tberrors.ErrEtagMismatch
 	}
 
-	if existing.UserID != *user {
-		if !existing.OrgEdit || existing.OrgID != *org {
-			return nil, tberrors.ErrUnauthorized
+	if len(fields) == 0 {
+		fields = model.AllCollectionFields
+	}
+
+	out := *existing
+	for _, f := range fields {
+		switch f {
+		case model.FieldTitle:
+			out.Title = c.Title
+		case model.FieldData:
+			out.Data = c.Data
+		case model.FieldOrgView:
+			out.OrgView = c.OrgView
+		case model.FieldOrgEdit:
+			out.OrgEdit = c.OrgEdit
+		case model.FieldOrgShare:
+			out.OrgShare = c.OrgShare
 		}
 	}
 
-	c.UserID = existing.UserID
-	c.OrgID = existing.OrgID
+	out.DataSize = int64(len(out.Data))
+	out.UpdatedAt = time.Now().UTC()
+	out.LastModifiedBy = *user
+	out.Version = existing.Version + 1
 
-	m.collections[existing.ID] = c
-	return c, nil
+	m.collections[existing.ID] = &out
+	m.revisions[existing.ID] = append(m.revisions[existing.ID], model.NewRevision(&out, *org))
+	m.recordEvent(model.NewAccessEvent(ctx, model.ActionUpdate, out.ID.String(), user, org))
+	return &out, nil
+}
+
+// ListCollections implements [datastore.CollectionStore].
+func (m *memStore) ListCollections(ctx context.Context, filter model.CollectionFilter, after *uuid.UUID, limit int, user, org *uuid.UUID) ([]*model.Collection, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	out := []*model.Collection{}
+	for _, c := range m.collections {
+		if c.DeletedAt != nil {
+			continue
+		}
+
+		if after != nil && bytes.Compare(c.ID[:], after[:]) <= 0 {
+			continue
+		}
+
+		switch filter {
+		case model.FilterOwned:
+			if c.UserID != *user {
+				continue
+			}
+		case model.FilterOrgShared:
+			if c.UserID == *user || !c.OrgView || c.OrgID != *org {
+				continue
+			}
+		case model.FilterGranted:
+			if c.UserID == *user || m.effectiveGrant(c.ID, user) == nil {
+				continue
+			}
+		default:
+			if !m.related(c, user, org) {
+				continue
+			}
+		}
+
+		out = append(out, c)
+	}
+
+	slices.SortFunc(out, func(a, b *model.Collection) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	if len(out) > limit {
+		out = out[:limit]
+	}
+
+	return out, nil
+}
+
+// related reports whether the user owns c, is in its org or has a grant on it, which are the only collections
+// a policy is asked about when listing. The lock must be held
+func (m *memStore) related(c *model.Collection, user, org *uuid.UUID) bool {
+	return c.UserID == *user || c.OrgID == *org || m.effectiveGrant(c.ID, user) != nil
+}
+
+// effectiveGrant combines the user's grant on a collection with those of their groups, returning nil when
+// they have none. The lock must be held
+func (m *memStore) effectiveGrant(id uuid.UUID, user *uuid.UUID) *model.Grant {
+	var out *model.Grant
+	if g, ok := m.grants[id][*user]; ok {
+		copied := *g
+		out = &copied
+	}
+
+	for group, g := range m.groupGrants[id] {
+		if _, ok := m.members[group][*user]; !ok {
+			continue
+		}
+
+		if out == nil {
+			out = &model.Grant{CollectionID: id, UserID: *user}
+		}
+		out.CanEdit = out.CanEdit || g.CanEdit
+		out.CanShare = out.CanShare || g.CanShare
+	}
+
+	return out
 }
@@ -0,0 +1,189 @@
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+)
+
+// CreateGroup implements [datastore.GroupStore].
+func (m *memStore) CreateGroup(ctx context.Context, name string, user, org *uuid.UUID) (*model.Group, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, g := range m.groups {
+		if g.OrgID == *org && g.Name == name {
+			return nil, tberrors.ErrGroupExists
+		}
+	}
+
+	g := &model.Group{
+		ID:        uuid.New(),
+		OrgID:     *org,
+		Name:      name,
+		CreatedBy: *user,
+		CreatedAt: time.Now().UTC(),
+	}
+	m.groups[g.ID] = g
+
+	copied := *g
+	return &copied, nil
+}
+
+// ListGroups implements [datastore.GroupStore].
+func (m *memStore) ListGroups(ctx context.Context, user, org *uuid.UUID) ([]*model.Group, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	out := []*model.Group{}
+	for _, g := range m.groups {
+		if g.OrgID != *org {
+			continue
+		}
+
+		copied := *g
+		out = append(out, &copied)
+	}
+
+	slices.SortFunc(out, func(a, b *model.Group) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return out, nil
+}
+
+// GetGroup implements [datastore.GroupStore].
+func (m *memStore) GetGroup(ctx context.Context, id *uuid.UUID) (*model.Group, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	g, ok := m.groups[*id]
+	if !ok {
+		return nil, tberrors.ErrGroupNotFound
+	}
+
+	copied := *g
+	return &copied, nil
+}
+
+// DeleteGroup implements [datastore.GroupStore].
+func (m *memStore) DeleteGroup(ctx context.Context, id, user, org *uuid.UUID) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.groups[*id]; !ok {
+		return tberrors.ErrGroupNotFound
+	}
+
+	delete(m.groups, *id)
+	delete(m.members, *id)
+
+	// Cascade delete
+	for _, grants := range m.groupGrants {
+		delete(grants, *id)
+	}
+
+	e := model.NewAccessEvent(ctx, model.ActionDeleteGroup, "", user, org)
+	e.GroupID = id
+	m.recordEvent(e)
+
+	return nil
+}
+
+// AddGroupMember implements [datastore.GroupStore].
+func (m *memStore) AddGroupMember(ctx context.Context, id, member, user, org *uuid.UUID) (*model.GroupMember, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.groups[*id]; !ok {
+		return nil, tberrors.ErrGroupNotFound
+	}
+
+	out, ok := m.members[*id][*member]
+	if !ok {
+		out = &model.GroupMember{
+			GroupID:   *id,
+			UserID:    *member,
+			AddedBy:   *user,
+			CreatedAt: time.Now().UTC(),
+		}
+
+		if m.members[*id] == nil {
+			m.members[*id] = map[uuid.UUID]*model.GroupMember{}
+		}
+		m.members[*id][*member] = out
+
+		e := model.NewAccessEvent(ctx, model.ActionAddGroupMember, "", user, org)
+		e.GroupID = id
+		e.GranteeID = member
+		m.recordEvent(e)
+	}
+
+	copied := *out
+	return &copied, nil
+}
+
+// RemoveGroupMember implements [datastore.GroupStore].
+func (m *memStore) RemoveGroupMember(ctx context.Context, id, member, user, org *uuid.UUID) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.groups[*id]; !ok {
+		return tberrors.ErrGroupNotFound
+	}
+
+	if _, ok := m.members[*id][*member]; !ok {
+		return tberrors.ErrMemberNotFound
+	}
+
+	delete(m.members[*id], *member)
+
+	e := model.NewAccessEvent(ctx, model.ActionRemoveGroupMember, "", user, org)
+	e.GroupID = id
+	e.GranteeID = member
+	m.recordEvent(e)
+
+	return nil
+}
+
+// ListGroupMembers implements [datastore.GroupStore].
+func (m *memStore) ListGroupMembers(ctx context.Context, id, user, org *uuid.UUID) ([]*model.GroupMember, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.groups[*id]; !ok {
+		return nil, tberrors.ErrGroupNotFound
+	}
+
+	out := []*model.GroupMember{}
+	for _, member := range m.members[*id] {
+		copied := *member
+		out = append(out, &copied)
+	}
+
+	slices.SortFunc(out, func(a, b *model.GroupMember) int {
+		if n := a.CreatedAt.Compare(b.CreatedAt); n != 0 {
+			return n
+		}
+		return bytes.Compare(a.UserID[:], b.UserID[:])
+	})
+
+	return out, nil
+}
+
+// getGroup looks up a group in the org, groups in other orgs are not found. The lock must be held
+func (m *memStore) getGroup(id, org *uuid.UUID) (*model.Group, error) {
+	g, ok := m.groups[*id]
+	if !ok || g.OrgID != *org {
+		return nil, tberrors.ErrGroupNotFound
+	}
+
+	return g, nil
+}
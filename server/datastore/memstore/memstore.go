@@ -6,20 +6,95 @@ import (
 
 	"testbert/server/datastore"
 	"testbert/server/model"
+	"testbert/server/sharetoken"
+	"testbert/server/tberrors"
 
 	"github.com/google/uuid"
 )
 
 type memStore struct {
-	collections   map[uuid.UUID]*model.Collection
+	collections map[uuid.UUID]*model.Collection
+	// sharingTokens Keyed by the token hash
 	sharingTokens map[string]*model.SharingToken
-	lock          sync.Mutex
+	revisions     map[uuid.UUID][]*model.Revision
+	// grants Keyed by collection then grantee
+	grants map[uuid.UUID]map[uuid.UUID]*model.Grant
+	// groupGrants Keyed by collection then group
+	groupGrants map[uuid.UUID]map[uuid.UUID]*model.Grant
+	groups      map[uuid.UUID]*model.Group
+	// members Keyed by group then member
+	members map[uuid.UUID]map[uuid.UUID]*model.GroupMember
+	// transfers Offers waiting to be accepted, keyed by collection
+	transfers map[uuid.UUID]*model.Transfer
+	// apiKeys Keyed by the key hash
+	apiKeys map[string]*model.APIKey
+	// outbox Unsent access events, oldest first
+	outbox      []*model.AccessEvent
+	lastEventID int64
+	// audit Every access event, oldest first
+	audit       []*model.AccessEvent
+	lastAuditID int64
+	hasher      *sharetoken.Hasher
+	// apiKeyHasher Hashes API keys, with a different key to sharing tokens
+	apiKeyHasher *sharetoken.Hasher
+	lock         sync.Mutex
 }
 
-func NewMemStore() datastore.TestBertDatastore {
+func NewMemStore(hasher, apiKeyHasher *sharetoken.Hasher) datastore.TestBertDatastore {
 	return &memStore{
 		collections:   map[uuid.UUID]*model.Collection{},
 		sharingTokens: map[string]*model.SharingToken{},
+		revisions:     map[uuid.UUID][]*model.Revision{},
+		grants:        map[uuid.UUID]map[uuid.UUID]*model.Grant{},
+		groupGrants:   map[uuid.UUID]map[uuid.UUID]*model.Grant{},
+		groups:        map[uuid.UUID]*model.Group{},
+		members:       map[uuid.UUID]map[uuid.UUID]*model.GroupMember{},
+		transfers:     map[uuid.UUID]*model.Transfer{},
+		apiKeys:       map[string]*model.APIKey{},
+		hasher:        hasher,
+		apiKeyHasher:  apiKeyHasher,
 		lock:          sync.Mutex{},
 	}
 }
+
+// liveCollection looks up a collection that has not been moved to the trash
+func (m *memStore) liveCollection(id *uuid.UUID) (*model.Collection, bool) {
+	c, ok := m.collections[*id]
+	if !ok || c.DeletedAt != nil {
+		return nil, false
+	}
+
+	return c, true
+}
+
+// checkCollection looks up a collection that is in the trash when trashed is true, or live otherwise, and
+// authorizes the change to it with check. The lock must be held
+func (m *memStore) checkCollection(id, user *uuid.UUID, trashed bool, check datastore.CollectionCheck) (*model.Collection, error) {
+	c, ok := m.collections[*id]
+	if !ok || (c.DeletedAt != nil) != trashed {
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	if err := check(c, m.effectiveGrant(c.ID, user)); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// recordEvent adds e to the outbox and the audit log, the lock must be held
+func (m *memStore) recordEvent(e *model.AccessEvent) {
+	m.recordAudit(e)
+
+	m.lastEventID++
+	e.ID = m.lastEventID
+	m.outbox = append(m.outbox, e)
+}
+
+// recordAudit adds a copy of e to the audit log, the lock must be held
+func (m *memStore) recordAudit(e *model.AccessEvent) {
+	m.lastAuditID++
+	out := *e
+	out.ID = m.lastAuditID
+	m.audit = append(m.audit, &out)
+}
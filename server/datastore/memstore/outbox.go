@@ -0,0 +1,38 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"testbert/server/model"
+)
+
+// RelayEvents implements [datastore.OutboxStore]. Only one relay may run at a time.
+func (m *memStore) RelayEvents(ctx context.Context, limit int, deliver func(*model.AccessEvent) error) (int, error) {
+	m.lock.Lock()
+	pending := slices.Clone(m.outbox[:min(limit, len(m.outbox))])
+	m.lock.Unlock()
+
+	// deliver without holding the lock so a slow publisher doesn't stall the store
+	sent := 0
+	var err error
+	for _, e := range pending {
+		if err = deliver(e); err != nil {
+			break
+		}
+		sent++
+	}
+
+	m.lock.Lock()
+	m.outbox = m.outbox[sent:]
+	m.lock.Unlock()
+
+	return sent, err
+}
+
+// PurgeSentEvents implements [datastore.OutboxStore]. Events leave the outbox as soon as they are sent, so there
+// is nothing to purge.
+func (m *memStore) PurgeSentEvents(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,91 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"testbert/server/datastore"
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+)
+
+// ListRevisions implements [datastore.RevisionStore].
+func (m *memStore) ListRevisions(ctx context.Context, collectionID *uuid.UUID, before int64, limit int, user, org *uuid.UUID) ([]*model.Revision, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.liveCollection(collectionID); !ok {
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	out := []*model.Revision{}
+	revisions := m.revisions[*collectionID]
+	for i := len(revisions) - 1; i >= 0 && len(out) < limit; i-- {
+		if before != 0 && revisions[i].Revision >= before {
+			continue
+		}
+		out = append(out, revisions[i])
+	}
+
+	return out, nil
+}
+
+// GetRevision implements [datastore.RevisionStore].
+func (m *memStore) GetRevision(ctx context.Context, collectionID *uuid.UUID, revision int64, user, org *uuid.UUID) (*model.Revision, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.liveCollection(collectionID); !ok {
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	return m.findRevision(collectionID, revision)
+}
+
+// RestoreRevision implements [datastore.RevisionStore].
+func (m *memStore) RestoreRevision(ctx context.Context, collectionID *uuid.UUID, revision, version int64, user, org *uuid.UUID, check datastore.CollectionCheck) (*model.Collection, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	existing, err := m.checkCollection(collectionID, user, false, check)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := m.findRevision(collectionID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && version != existing.Version {
+		return nil, tberrors.ErrEtagMismatch
+	}
+
+	out := *existing
+	out.Title = r.Title
+	out.Data = r.Data
+	out.OrgView = r.OrgView
+	out.OrgEdit = r.OrgEdit
+	out.OrgShare = r.OrgShare
+	out.DataSize = int64(len(out.Data))
+	out.UpdatedAt = time.Now().UTC()
+	out.LastModifiedBy = *user
+	out.Version = existing.Version + 1
+
+	m.collections[existing.ID] = &out
+	m.revisions[existing.ID] = append(m.revisions[existing.ID], model.NewRevision(&out, *org))
+	m.recordEvent(model.NewAccessEvent(ctx, model.ActionRestore, out.ID.String(), user, org))
+	return &out, nil
+}
+
+func (m *memStore) findRevision(collectionID *uuid.UUID, revision int64) (*model.Revision, error) {
+	for _, r := range m.revisions[*collectionID] {
+		if r.Revision == revision {
+			return r, nil
+		}
+	}
+
+	return nil, tberrors.ErrRevisionNotFound
+}
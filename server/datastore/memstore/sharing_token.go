@@ -1,61 +1,143 @@
 package memstore
 
 import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"testbert/server/datastore"
 	"testbert/server/model"
+	"testbert/server/sharetoken"
 	"testbert/server/tberrors"
 
 	"github.com/google/uuid"
 )
 
 // CreateSharingToken implements [datastore.SharingTokenStore].
-func (m *memStore) CreateSharingToken(collectionID, user, org *uuid.UUID) (*model.SharingToken, error) {
+func (m *memStore) CreateSharingToken(ctx context.Context, in *model.SharingToken, user, org *uuid.UUID, check datastore.CollectionCheck) (*model.SharingToken, error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	c, ok := m.collections[*collectionID]
-	if !ok {
-		return nil, tberrors.ErrCollectionNotFound
-	}
-
-	if c.UserID != *user {
-		if !c.OrgShare || c.OrgID != *org {
-			return nil, tberrors.ErrUnauthorized
-		}
+	if _, err := m.checkCollection(&in.CollectionID, user, false, check); err != nil {
+		return nil, err
 	}
 
+	token := sharetoken.NewToken()
 	t := &model.SharingToken{
-		Token:        uuid.NewString(),
-		CollectionID: *collectionID,
+		TokenID:      sharetoken.NewID(),
+		TokenHash:    m.hasher.Hash(token),
+		CollectionID: in.CollectionID,
 		UserID:       *user,
 		OrgID:        *org,
+		ExpiresAt:    in.ExpiresAt,
+		MaxUses:      in.MaxUses,
+		CreatedAt:    time.Now().UTC(),
 	}
 
-	m.sharingTokens[t.Token] = t
-	return t, nil
+	m.sharingTokens[t.TokenHash] = t
+
+	e := model.NewAccessEvent(ctx, model.ActionShare, t.CollectionID.String(), user, org)
+	e.TokenID = t.TokenID
+	e.TokenFingerprint = sharetoken.FingerprintFromHash(t.TokenHash)
+	m.recordEvent(e)
+
+	out := *t
+	out.Token = token
+	return &out, nil
 }
 
-// DeleteSharingToken implements [datastore.SharingTokenStore].
-func (m *memStore) DeleteSharingToken(token string, user, org *uuid.UUID) error {
+// GetSharingToken implements [datastore.SharingTokenStore].
+func (m *memStore) GetSharingToken(ctx context.Context, token string) (*model.SharingToken, error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	t, ok := m.sharingTokens[token]
+	t, ok := m.sharingTokens[m.hasher.Hash(token)]
 	if !ok {
-		return tberrors.ErrTokenNotFound
+		return nil, tberrors.ErrTokenNotFound
 	}
 
-	c, ok := m.collections[t.CollectionID]
+	out := *t
+	out.TokenHash = ""
+	return &out, nil
+}
+
+// GetSharingTokenByID implements [datastore.SharingTokenStore].
+func (m *memStore) GetSharingTokenByID(ctx context.Context, id string) (*model.SharingToken, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	t, ok := m.sharingTokenByID(id)
 	if !ok {
-		delete(m.sharingTokens, t.Token)
-		return nil
+		return nil, tberrors.ErrTokenNotFound
 	}
 
-	if t.UserID != *user {
-		if !c.OrgShare || t.OrgID != *org {
-			return tberrors.ErrUnauthorized
-		}
+	out := *t
+	out.TokenHash = ""
+	return &out, nil
+}
+
+// DeleteSharingTokenByID implements [datastore.SharingTokenStore].
+func (m *memStore) DeleteSharingTokenByID(ctx context.Context, id string, user, org *uuid.UUID, check datastore.CollectionCheck) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	t, ok := m.sharingTokenByID(id)
+	if !ok {
+		return tberrors.ErrTokenNotFound
 	}
 
-	delete(m.sharingTokens, token)
+	_, err := m.checkCollection(&t.CollectionID, user, false, check)
+	if err == tberrors.ErrCollectionNotFound {
+		return tberrors.ErrTokenNotFound
+	} else if err != nil {
+		return err
+	}
+
+	delete(m.sharingTokens, t.TokenHash)
+
+	e := model.NewAccessEvent(ctx, model.ActionRevoke, t.CollectionID.String(), user, org)
+	e.TokenID = t.TokenID
+	e.TokenFingerprint = sharetoken.FingerprintFromHash(t.TokenHash)
+	m.recordEvent(e)
 	return nil
 }
+
+// sharingTokenByID looks up a token by its identifier, the lock must be held
+func (m *memStore) sharingTokenByID(id string) (*model.SharingToken, bool) {
+	for _, t := range m.sharingTokens {
+		if t.TokenID == id {
+			return t, true
+		}
+	}
+
+	return nil, false
+}
+
+// ListSharingTokens implements [datastore.SharingTokenStore].
+func (m *memStore) ListSharingTokens(ctx context.Context, collectionID, user, org *uuid.UUID) ([]*model.SharingToken, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.liveCollection(collectionID); !ok {
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	out := []*model.SharingToken{}
+	for _, t := range m.sharingTokens {
+		if t.CollectionID == *collectionID {
+			copied := *t
+			copied.TokenHash = ""
+			out = append(out, &copied)
+		}
+	}
+
+	slices.SortFunc(out, func(a, b *model.SharingToken) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.TokenID, b.TokenID)
+	})
+
+	return out, nil
+}
@@ -0,0 +1,118 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"testbert/server/datastore"
+	"testbert/server/model"
+	"testbert/server/sharetoken"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+)
+
+// TransferOwnership implements [datastore.TransferStore].
+func (m *memStore) TransferOwnership(ctx context.Context, t *model.Transfer, user, org *uuid.UUID, check datastore.CollectionCheck) (*model.Collection, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	c, err := m.checkCollection(&t.CollectionID, user, false, check)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.ToUserID == c.UserID {
+		return nil, tberrors.ErrInvalidTransfer
+	}
+
+	offer := &model.Transfer{
+		CollectionID: c.ID,
+		FromUserID:   c.UserID,
+		ToUserID:     t.ToUserID,
+		ToOrgID:      t.ToOrgID,
+		OfferedAt:    time.Now().UTC(),
+	}
+	if offer.ToOrgID == uuid.Nil {
+		offer.ToOrgID = c.OrgID
+	}
+
+	if offer.MovesOrg(c) {
+		m.transfers[c.ID] = offer
+
+		e := model.NewAccessEvent(ctx, model.ActionTransferOffered, c.ID.String(), user, org)
+		e.GranteeID = &offer.ToUserID
+		m.recordEvent(e)
+		return nil, nil
+	}
+
+	return m.completeTransfer(ctx, c, offer, user, org), nil
+}
+
+// AcceptTransfer implements [datastore.TransferStore].
+func (m *memStore) AcceptTransfer(ctx context.Context, collectionID, user, org *uuid.UUID) (*model.Collection, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	t, ok := m.transfers[*collectionID]
+	if !ok || t.ToUserID != *user || t.ToOrgID != *org {
+		return nil, tberrors.ErrTransferNotFound
+	}
+
+	c, ok := m.liveCollection(collectionID)
+	if !ok {
+		return nil, tberrors.ErrTransferNotFound
+	}
+
+	// The offer lapses if someone else took ownership in the meantime
+	if c.UserID != t.FromUserID {
+		return nil, tberrors.ErrTransferNotFound
+	}
+
+	return m.completeTransfer(ctx, c, t, user, org), nil
+}
+
+// completeTransfer hands c to its new owner, revoking the sharing tokens and access that don't carry over. The
+// lock must be held
+func (m *memStore) completeTransfer(ctx context.Context, c *model.Collection, t *model.Transfer, user, org *uuid.UUID) *model.Collection {
+	for k, st := range m.sharingTokens {
+		if st.CollectionID != c.ID || !t.RevokesToken(c, st) {
+			continue
+		}
+
+		delete(m.sharingTokens, k)
+
+		e := model.NewAccessEvent(ctx, model.ActionRevoke, c.ID.String(), user, org)
+		e.TokenID = st.TokenID
+		e.TokenFingerprint = sharetoken.FingerprintFromHash(st.TokenHash)
+		m.recordEvent(e)
+	}
+
+	out := *c
+	if t.MovesOrg(c) {
+		// Org permissions and group grants belong to the old org
+		out.OrgView = false
+		out.OrgEdit = false
+		out.OrgShare = false
+		delete(m.groupGrants, c.ID)
+	}
+
+	out.UserID = t.ToUserID
+	out.OrgID = t.ToOrgID
+	out.UpdatedAt = time.Now().UTC()
+	out.LastModifiedBy = *user
+	out.Version = c.Version + 1
+
+	// The new owner no longer needs a grant
+	delete(m.grants[c.ID], t.ToUserID)
+	delete(m.transfers, c.ID)
+	m.collections[c.ID] = &out
+
+	owner := t.ToUserID
+	e := model.NewAccessEvent(ctx, model.ActionTransfer, c.ID.String(), user, org)
+	e.GranteeID = &owner
+	m.recordEvent(e)
+
+	copied := out
+	return &copied
+}
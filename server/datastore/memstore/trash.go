@@ -0,0 +1,107 @@
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"time"
+
+	"testbert/server/datastore"
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+)
+
+// ListTrash implements [datastore.TrashStore].
+func (m *memStore) ListTrash(ctx context.Context, after *uuid.UUID, limit int, user, org *uuid.UUID) ([]*model.Collection, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	out := []*model.Collection{}
+	for _, c := range m.collections {
+		if c.DeletedAt == nil || !m.related(c, user, org) {
+			continue
+		}
+
+		if after != nil && bytes.Compare(c.ID[:], after[:]) <= 0 {
+			continue
+		}
+
+		out = append(out, c)
+	}
+
+	slices.SortFunc(out, func(a, b *model.Collection) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	if len(out) > limit {
+		out = out[:limit]
+	}
+
+	return out, nil
+}
+
+// GetCollectionIncludingTrash implements [datastore.TrashStore].
+func (m *memStore) GetCollectionIncludingTrash(ctx context.Context, id *uuid.UUID) (*model.Collection, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	c, ok := m.collections[*id]
+	if !ok {
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	return c, nil
+}
+
+// RestoreCollection implements [datastore.TrashStore].
+func (m *memStore) RestoreCollection(ctx context.Context, id, user, org *uuid.UUID, check datastore.CollectionCheck) (*model.Collection, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	c, err := m.checkCollection(id, user, true, check)
+	if err != nil {
+		return nil, err
+	}
+
+	out := *c
+	out.DeletedAt = nil
+	out.DeletedBy = nil
+	out.Version = c.Version + 1
+
+	m.collections[out.ID] = &out
+	m.recordEvent(model.NewAccessEvent(ctx, model.ActionUndelete, out.ID.String(), user, org))
+	return &out, nil
+}
+
+// PurgeTrash implements [datastore.TrashStore].
+func (m *memStore) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var count int64
+	for id, c := range m.collections {
+		if c.DeletedAt == nil || !c.DeletedAt.Before(before) {
+			continue
+		}
+
+		delete(m.collections, id)
+		m.recordEvent(model.NewAccessEvent(ctx, model.ActionPurge, id.String(), nil, &c.OrgID))
+		delete(m.revisions, id)
+		delete(m.grants, id)
+		delete(m.groupGrants, id)
+		delete(m.transfers, id)
+
+		// Cascade delete
+		for k, t := range m.sharingTokens {
+			if t.CollectionID == id {
+				delete(m.sharingTokens, k)
+			}
+		}
+
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,20 @@
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"testbert/server/model"
+)
+
+// OutboxStore Access events are recorded by the store in the same transaction as the change they describe
+// and wait in the outbox until they are relayed
+type OutboxStore interface {
+	// RelayEvents claims up to limit unsent events, oldest first, passes them to deliver and marks the delivered
+	// ones as sent. No transaction is held during delivery. Delivery stops at the first error, which is returned
+	// along with the number of events sent, and the rest of the batch is released for the next relay
+	RelayEvents(ctx context.Context, limit int, deliver func(*model.AccessEvent) error) (int, error)
+	// PurgeSentEvents removes events sent before the given time from the outbox, returning how many were
+	// removed. They stay in the audit log
+	PurgeSentEvents(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,19 @@
+package datastore
+
+import (
+	"context"
+
+	"testbert/server/model"
+
+	"github.com/google/uuid"
+)
+
+type RevisionStore interface {
+	// ListRevisions returns up to limit revisions of a collection, newest first, starting before the given
+	// revision when it is not zero
+	ListRevisions(ctx context.Context, collectionID *uuid.UUID, before int64, limit int, user, org *uuid.UUID) ([]*model.Revision, error)
+	GetRevision(ctx context.Context, collectionID *uuid.UUID, revision int64, user, org *uuid.UUID) (*model.Revision, error)
+	// RestoreRevision copies a revision back onto its collection, recording the result as a new revision. It
+	// fails with an etag mismatch when version is not zero and does not match the stored version
+	RestoreRevision(ctx context.Context, collectionID *uuid.UUID, revision, version int64, user, org *uuid.UUID, check CollectionCheck) (*model.Collection, error)
+}
@@ -1,12 +1,25 @@
 package datastore
 
 import (
+	"context"
+
 	"testbert/server/model"
 
 	"github.com/google/uuid"
 )
 
 type SharingTokenStore interface {
-	CreateSharingToken(collectionID, user, org *uuid.UUID) (*model.SharingToken, error)
-	DeleteSharingToken(t string, user, org *uuid.UUID) error
+	// CreateSharingToken creates a token for t.CollectionID limited by t.ExpiresAt and t.MaxUses, only the
+	// returned token carries the raw secret
+	CreateSharingToken(ctx context.Context, t *model.SharingToken, user, org *uuid.UUID, check CollectionCheck) (*model.SharingToken, error)
+	// GetSharingToken looks up a token by its raw secret, returning it without the secret or its hash
+	GetSharingToken(ctx context.Context, t string) (*model.SharingToken, error)
+	// GetSharingTokenByID looks up a token using the identifier returned by ListSharingTokens
+	GetSharingTokenByID(ctx context.Context, id string) (*model.SharingToken, error)
+	// DeleteSharingTokenByID revokes a token using the identifier returned by ListSharingTokens, check is
+	// given the token's collection. Tokens for collections in the trash are not found
+	DeleteSharingTokenByID(ctx context.Context, id string, user, org *uuid.UUID, check CollectionCheck) error
+	// ListSharingTokens returns every token for a collection, oldest first, without the raw token. Callers
+	// check the user may see them first, as nothing is changed
+	ListSharingTokens(ctx context.Context, collectionID, user, org *uuid.UUID) ([]*model.SharingToken, error)
 }
@@ -0,0 +1,123 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+)
+
+// CreateAPIKey implements [datastore.TestBertDatastore].
+func (s *sqlStore) CreateAPIKey(ctx context.Context, name string, user *uuid.UUID, org *uuid.UUID) (*model.APIKey, error) {
+	query := `
+	INSERT INTO api_keys(id, org_id, name, key_hash, created_by)
+	VALUES($1, $2, $3, $4, $5)
+	RETURNING id, org_id, name, created_by, created_at, last_used_at;`
+
+	key := model.NewAPIKey()
+	out := &model.APIKey{}
+	err := s.db.GetContext(ctx, out, query, uuid.New(), org, name, s.apiKeyHasher.Hash(key), user)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	out.Key = key
+	return out, nil
+}
+
+// ListAPIKeys implements [datastore.TestBertDatastore].
+func (s *sqlStore) ListAPIKeys(ctx context.Context, org *uuid.UUID) ([]*model.APIKey, error) {
+	query := `
+	SELECT id, org_id, name, created_by, created_at, last_used_at
+	FROM api_keys
+	WHERE org_id = $1
+	ORDER BY created_at, id;`
+
+	out := []*model.APIKey{}
+	err := s.db.SelectContext(ctx, &out, query, org)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// GetAPIKey implements [datastore.TestBertDatastore].
+func (s *sqlStore) GetAPIKey(ctx context.Context, id *uuid.UUID) (*model.APIKey, error) {
+	query := `
+	SELECT id, org_id, name, created_by, created_at, last_used_at
+	FROM api_keys
+	WHERE id = $1;`
+
+	out := &model.APIKey{}
+	err := s.db.GetContext(ctx, out, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tberrors.ErrAPIKeyNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	return out, nil
+}
+
+// UseAPIKey implements [datastore.TestBertDatastore].
+func (s *sqlStore) UseAPIKey(ctx context.Context, key string) (*model.APIKey, error) {
+	// The key is only written when the recorded use is stale, otherwise it is read as it is
+	query := `
+	WITH used AS (
+		UPDATE api_keys
+		SET last_used_at = now()
+		WHERE key_hash = $1
+		  AND (last_used_at IS NULL OR last_used_at < now() - make_interval(secs => $2))
+		RETURNING id, org_id, name, created_by, created_at, last_used_at
+	)
+	SELECT id, org_id, name, created_by, created_at, last_used_at
+	FROM used
+	UNION ALL
+	SELECT id, org_id, name, created_by, created_at, last_used_at
+	FROM api_keys
+	WHERE key_hash = $1
+	  AND NOT EXISTS (SELECT 1 FROM used);`
+
+	out := &model.APIKey{}
+	err := s.db.GetContext(ctx, out, query, s.apiKeyHasher.Hash(key), model.APIKeyUseInterval.Seconds())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tberrors.ErrAPIKeyNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	return out, nil
+}
+
+// DeleteAPIKey implements [datastore.TestBertDatastore].
+func (s *sqlStore) DeleteAPIKey(ctx context.Context, id *uuid.UUID) error {
+	query := `
+	DELETE
+	FROM api_keys
+	WHERE id = $1;`
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	if count, _ := result.RowsAffected(); count == 0 {
+		return tberrors.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// RecordAccess implements [datastore.TestBertDatastore].
+func (s *sqlStore) RecordAccess(ctx context.Context, e *model.AccessEvent) error {
+	return insertAuditEvent(ctx, s.db, e)
+}
+
+// ListAuditEvents implements [datastore.TestBertDatastore].
+func (s *sqlStore) ListAuditEvents(ctx context.Context, collectionID *uuid.UUID, filter *model.AuditFilter, before int64, limit int, user *uuid.UUID, org *uuid.UUID) ([]*model.AccessEvent, error) {
+	query := `
+	SELECT id, action, collection_id, user_id, org_id, token_fingerprint, token_id, occurred_at,
+		trace_id, span_id, peer_address, user_agent, grpc_method, grantee_id, group_id, org_admin,
+		service_principal
+	FROM audit_events
+	WHERE collection_id = $1
+	  AND ($2::BIGINT = 0 OR id < $2)
+	  AND (COALESCE(cardinality($3::TEXT[]), 0) = 0 OR action = ANY($3))
+	  AND ($4::TIMESTAMPTZ IS NULL OR occurred_at >= $4)
+	  AND ($5::TIMESTAMPTZ IS NULL OR occurred_at < $5)
+	ORDER BY id DESC
+	LIMIT $6;`
+
+	since := sql.NullTime{Time: filter.Since, Valid: !filter.Since.IsZero()}
+	until := sql.NullTime{Time: filter.Until, Valid: !filter.Until.IsZero()}
+
+	out := []*model.AccessEvent{}
+	err := s.db.SelectContext(ctx, &out, query, collectionID.String(), before, pq.Array(filter.Actions), since, until, limit)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// insertAuditEvent adds e to the audit log, either directly or as part of a transaction
+func insertAuditEvent(ctx context.Context, db sqlx.ExtContext, e *model.AccessEvent) error {
+	query := `
+	INSERT INTO audit_events(action, collection_id, user_id, org_id, token_fingerprint, token_id, occurred_at,
+		trace_id, span_id, peer_address, user_agent, grpc_method, grantee_id, group_id, org_admin,
+		service_principal)
+	VALUES(:action, :collection_id, :user_id, :org_id, :token_fingerprint, :token_id, :occurred_at,
+		:trace_id, :span_id, :peer_address, :user_agent, :grpc_method, :grantee_id, :group_id, :org_admin,
+		:service_principal);`
+
+	_, err := sqlx.NamedExecContext(ctx, db, query, e)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	return nil
+}
@@ -1,64 +1,144 @@
 package sqlstore
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"slices"
+	"strings"
 
+	"testbert/server/datastore"
 	"testbert/server/model"
+	"testbert/server/sharetoken"
 	"testbert/server/tberrors"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 )
 
 // CreateCollection implements [datastore.TestBertDatastore].
-func (s *sqlStore) CreateCollection(c *model.Collection, user *uuid.UUID, org *uuid.UUID) (*model.Collection, error) {
+func (s *sqlStore) CreateCollection(ctx context.Context, c *model.Collection, user *uuid.UUID, org *uuid.UUID) (*model.Collection, error) {
 	c.ID = uuid.New()
 	c.UserID = *user
 	c.OrgID = *org
+	c.CreatedBy = *user
+	c.LastModifiedBy = *user
 
 	query := `
-	INSERT INTO collections(id, user_id, org_id, data, org_view, org_edit, org_share)
-	VALUES(:id, :user_id, :org_id, :data, :org_view, :org_edit, :org_share);`
-	_, err := s.db.NamedExec(query, c)
+	INSERT INTO collections(id, user_id, org_id, title, data, org_view, org_edit, org_share, created_by, last_modified_by)
+	VALUES(:id, :user_id, :org_id, :title, :data, :org_view, :org_edit, :org_share, :created_by, :last_modified_by)
+	RETURNING id, user_id, org_id, title, data, data_size, org_view, org_edit, org_share,
+		created_at, updated_at, created_by, last_modified_by, version, deleted_at, deleted_by;`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer stmt.Close()
+
+	out := &model.Collection{}
+	err = stmt.GetContext(ctx, out, c)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	err = insertRevision(ctx, tx, model.NewRevision(out, *org))
 	if err != nil {
+		return nil, err
+	}
+
+	err = insertEvent(ctx, tx, model.NewAccessEvent(ctx, model.ActionCreate, out.ID.String(), user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
 		log.Printf("database error: %v", err)
 		return nil, tberrors.ErrInternal
 	}
 
-	return c, nil
+	return out, nil
 }
 
 // DeleteCollection implements [datastore.TestBertDatastore].
-func (s *sqlStore) DeleteCollection(id *uuid.UUID, user *uuid.UUID, org *uuid.UUID) error {
+func (s *sqlStore) DeleteCollection(ctx context.Context, id *uuid.UUID, version int64, user *uuid.UUID, org *uuid.UUID, check datastore.CollectionCheck) error {
 	query := `
-	DELETE FROM collections 
-	WHERE id = $1
-	  AND (user_id = $2 OR (org_edit AND org_id = $3));`
+	UPDATE collections
+	SET deleted_at = now(),
+		deleted_by = $2,
+		version = version + 1
+	WHERE id = $1;`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	existing, err := lockCollection(ctx, tx, id, user, false, check)
+	if err != nil {
+		return err
+	}
+
+	if version != 0 && existing.Version != version {
+		return tberrors.ErrEtagMismatch
+	}
 
-	result, err := s.db.Exec(query, id, user, org)
+	_, err = tx.ExecContext(ctx, query, id, user)
 	if err != nil {
 		log.Printf("database error: %v", err)
 		return tberrors.ErrInternal
 	}
 
-	if count, _ := result.RowsAffected(); count == 0 {
-		return tberrors.ErrCollectionNotFound
+	err = insertEvent(ctx, tx, model.NewAccessEvent(ctx, model.ActionDelete, id.String(), user, org))
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
 	}
 
 	return nil
 }
 
 // GetCollection implements [datastore.TestBertDatastore].
-func (s *sqlStore) GetCollection(id *uuid.UUID, user *uuid.UUID, org *uuid.UUID) (*model.Collection, error) {
+func (s *sqlStore) GetCollection(ctx context.Context, id *uuid.UUID) (*model.Collection, error) {
+	return s.getCollection(ctx, id, false)
+}
+
+// GetCollectionIncludingTrash implements [datastore.TestBertDatastore].
+func (s *sqlStore) GetCollectionIncludingTrash(ctx context.Context, id *uuid.UUID) (*model.Collection, error) {
+	return s.getCollection(ctx, id, true)
+}
+
+// getCollection looks up a collection, only finding one in the trash when includeTrash is true
+func (s *sqlStore) getCollection(ctx context.Context, id *uuid.UUID, includeTrash bool) (*model.Collection, error) {
 	query := `
-	SELECT id, user_id, org_id, data, org_view, org_edit, org_share
+	SELECT id, user_id, org_id, title, data, data_size, org_view, org_edit, org_share,
+		created_at, updated_at, created_by, last_modified_by, version, deleted_at, deleted_by
 	FROM collections
 	WHERE id = $1
-	  AND (user_id = $2 OR (org_id = $3 AND org_view))`
+	  AND (deleted_at IS NULL OR $2::BOOL)`
 
 	out := &model.Collection{}
 
-	err := s.db.Get(out, query, id, user, org)
+	err := s.db.GetContext(ctx, out, query, id, includeTrash)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, tberrors.ErrCollectionNotFound
@@ -72,54 +152,217 @@ func (s *sqlStore) GetCollection(id *uuid.UUID, user *uuid.UUID, org *uuid.UUID)
 }
 
 // GetCollectionFromSharingToken implements [datastore.TestBertDatastore].
-func (s *sqlStore) GetCollectionFromSharingToken(token string) (*model.Collection, error) {
+func (s *sqlStore) GetCollectionFromSharingToken(ctx context.Context, token string) (*model.Collection, error) {
+	// Counting the use in the same statement keeps concurrent readers from going over max_uses
 	query := `
-	SELECT c.id, c.user_id, c.org_id, c.data, c.org_view, c.org_edit, c.org_share
+	UPDATE shared_tokens t
+	SET use_count = t.use_count + 1,
+		last_accessed_at = now()
 	FROM collections c
-		JOIN shared_tokens t ON c.id = t.collection_id
-	WHERE t.token = $1;`
+	WHERE t.token_hash = $1
+	  AND c.id = t.collection_id
+	  AND c.deleted_at IS NULL
+	  AND (t.expires_at IS NULL OR t.expires_at > now())
+	  AND (t.max_uses = 0 OR t.use_count < t.max_uses)
+	RETURNING c.id, c.user_id, c.org_id, c.title, c.data, c.data_size, c.org_view, c.org_edit, c.org_share,
+		c.created_at, c.updated_at, c.created_by, c.last_modified_by, c.version, c.deleted_at, c.deleted_by;`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	hash := s.hasher.Hash(token)
 
 	out := &model.Collection{}
-	err := s.db.Get(out, query, token)
+	err = tx.GetContext(ctx, out, query, hash)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, tberrors.ErrCollectionNotFound
+			return nil, tberrors.ErrTokenNotFound
 		} else {
 			log.Printf("database error: %v", err)
 			return nil, tberrors.ErrInternal
 		}
 	}
 
+	e := model.NewAccessEvent(ctx, model.ActionReadShared, out.ID.String(), nil, nil)
+	e.TokenFingerprint = sharetoken.FingerprintFromHash(hash)
+	err = insertEvent(ctx, tx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
 	return out, nil
 }
 
+// updatableColumns SET clauses for each field that can be changed by UpdateCollection
+var updatableColumns = map[model.CollectionField]string{
+	model.FieldTitle:    `title = :title`,
+	model.FieldData:     `data = :data`,
+	model.FieldOrgView:  `org_view = :org_view`,
+	model.FieldOrgEdit:  `org_edit = :org_edit`,
+	model.FieldOrgShare: `org_share = :org_share`,
+}
+
 // UpdateCollection implements [datastore.TestBertDatastore].
-func (s *sqlStore) UpdateCollection(c *model.Collection, user *uuid.UUID, org *uuid.UUID) (*model.Collection, error) {
-	existing, err := s.GetCollection(&c.ID, user, org)
-	if err != nil {
-		return nil, err
+func (s *sqlStore) UpdateCollection(ctx context.Context, c *model.Collection, fields []model.CollectionField, user *uuid.UUID, org *uuid.UUID, check datastore.CollectionCheck) (*model.Collection, error) {
+	c.LastModifiedBy = *user
+
+	if len(fields) == 0 {
+		fields = model.AllCollectionFields
 	}
 
-	if existing.UserID != *user {
-		if !existing.OrgEdit || existing.OrgID != *org {
-			return nil, tberrors.ErrUnauthorized
+	set := []string{}
+	for _, f := range fields {
+		if col := updatableColumns[f]; !slices.Contains(set, col) {
+			set = append(set, col)
 		}
 	}
 
 	query := `
 	UPDATE collections
-	SET data = :data,
-		org_view = :org_view,
-		org_edit = :org_edit,
-		org_share = :org_share
+	SET ` + strings.Join(set, ", ") + `,
+		updated_at = now(),
+		last_modified_by = :last_modified_by,
+		version = version + 1
 	WHERE id = :id
-		AND (user_id = :user_id OR (org_id = :org_id AND org_edit));`
+	RETURNING id, user_id, org_id, title, data, data_size, org_view, org_edit, org_share,
+		created_at, updated_at, created_by, last_modified_by, version, deleted_at, deleted_by;`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	existing, err := lockCollection(ctx, tx, &c.ID, user, false, check)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Version != 0 && existing.Version != c.Version {
+		return nil, tberrors.ErrEtagMismatch
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer stmt.Close()
+
+	out := &model.Collection{}
+	err = stmt.GetContext(ctx, out, c)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	err = insertRevision(ctx, tx, model.NewRevision(out, *org))
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertEvent(ctx, tx, model.NewAccessEvent(ctx, model.ActionUpdate, out.ID.String(), user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// lockCollection locks a collection for the rest of tx and authorizes the change to it with check, reading
+// the user's grants in tx as well so access can't change before the change is made. Collections in the trash
+// are only found when trashed is true
+func lockCollection(ctx context.Context, tx *sqlx.Tx, id *uuid.UUID, user *uuid.UUID, trashed bool, check datastore.CollectionCheck) (*model.Collection, error) {
+	query := `
+	SELECT id, user_id, org_id, title, data, data_size, org_view, org_edit, org_share,
+		created_at, updated_at, created_by, last_modified_by, version, deleted_at, deleted_by
+	FROM collections
+	WHERE id = $1
+	  AND (deleted_at IS NOT NULL) = $2
+	FOR UPDATE;`
+
+	out := &model.Collection{}
+	err := tx.GetContext(ctx, out, query, id, trashed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tberrors.ErrCollectionNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	g, err := lockGrant(ctx, tx, id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := check(out, g); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// related Matches collections the user with id $2 in the org with id $3 is related to, which are the only ones
+// a policy is asked about when listing
+const related = `(user_id = $2 OR org_id = $3
+	OR EXISTS (SELECT 1 FROM effective_grants g WHERE g.collection_id = collections.id AND g.user_id = $2))`
 
-	_, err = s.db.NamedExec(query, c)
+// ListCollections implements [datastore.TestBertDatastore].
+func (s *sqlStore) ListCollections(ctx context.Context, filter model.CollectionFilter, after *uuid.UUID, limit int, user *uuid.UUID, org *uuid.UUID) ([]*model.Collection, error) {
+	var visible string
+	switch filter {
+	case model.FilterOwned:
+		visible = `user_id = $2`
+	case model.FilterOrgShared:
+		visible = `user_id <> $2 AND org_id = $3 AND org_view`
+	case model.FilterGranted:
+		visible = `user_id <> $2 AND EXISTS (SELECT 1 FROM effective_grants g WHERE g.collection_id = collections.id AND g.user_id = $2)`
+	default:
+		visible = related
+	}
+
+	query := `
+	SELECT id, user_id, org_id, title, data, data_size, org_view, org_edit, org_share,
+		created_at, updated_at, created_by, last_modified_by, version, deleted_at, deleted_by
+	FROM collections
+	WHERE ($1::UUID IS NULL OR id > $1)
+	  AND deleted_at IS NULL
+	  AND ` + visible + `
+	ORDER BY id
+	LIMIT $4;`
+
+	cursor := uuid.NullUUID{}
+	if after != nil {
+		cursor = uuid.NullUUID{UUID: *after, Valid: true}
+	}
+
+	out := []*model.Collection{}
+	err := s.db.SelectContext(ctx, &out, query, cursor, user, org, limit)
 	if err != nil {
 		log.Printf("database error: %v", err)
 		return nil, tberrors.ErrInternal
 	}
 
-	return c, nil
+	return out, nil
 }
@@ -0,0 +1,260 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"testbert/server/datastore"
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// GrantAccess implements [datastore.TestBertDatastore].
+func (s *sqlStore) GrantAccess(ctx context.Context, g *model.Grant, user *uuid.UUID, org *uuid.UUID, check datastore.CollectionCheck) (*model.Grant, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err = lockCollection(ctx, tx, &g.CollectionID, user, false, check); err != nil {
+		return nil, err
+	}
+
+	// Granting again replaces the permissions but keeps when access was first given
+	var query string
+	args := []any{g.CollectionID, g.UserID, g.CanEdit, g.CanShare, user}
+	if g.GroupID != uuid.Nil {
+		if _, err := lockGroup(ctx, tx, &g.GroupID, org); err != nil {
+			return nil, err
+		}
+
+		query = `
+		INSERT INTO collection_group_grants(collection_id, group_id, can_edit, can_share, granted_by)
+		VALUES($1, $2, $3, $4, $5)
+		ON CONFLICT (collection_id, group_id) DO UPDATE
+		SET can_edit = EXCLUDED.can_edit,
+			can_share = EXCLUDED.can_share,
+			granted_by = EXCLUDED.granted_by
+		RETURNING collection_id, group_id, can_edit, can_share, granted_by, created_at;`
+		args[1] = g.GroupID
+	} else {
+		if g.UserID == *user {
+			return nil, tberrors.ErrInvalidGrantee
+		}
+
+		query = `
+		INSERT INTO collection_grants(collection_id, user_id, can_edit, can_share, granted_by)
+		VALUES($1, $2, $3, $4, $5)
+		ON CONFLICT (collection_id, user_id) DO UPDATE
+		SET can_edit = EXCLUDED.can_edit,
+			can_share = EXCLUDED.can_share,
+			granted_by = EXCLUDED.granted_by
+		RETURNING collection_id, user_id, can_edit, can_share, granted_by, created_at;`
+	}
+
+	out := &model.Grant{}
+	err = tx.GetContext(ctx, out, query, args...)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	e := model.NewAccessEvent(ctx, model.ActionGrant, out.CollectionID.String(), user, org)
+	e.SetGrantee(out)
+	err = insertEvent(ctx, tx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// RevokeAccess implements [datastore.TestBertDatastore].
+func (s *sqlStore) RevokeAccess(ctx context.Context, g *model.Grant, user *uuid.UUID, org *uuid.UUID, check datastore.CollectionCheck) error {
+	query := `
+	DELETE
+	FROM collection_grants
+	WHERE collection_id = $1
+	  AND user_id = $2;`
+	grantee := g.UserID
+	if g.GroupID != uuid.Nil {
+		query = `
+		DELETE
+		FROM collection_group_grants
+		WHERE collection_id = $1
+		  AND group_id = $2;`
+		grantee = g.GroupID
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err = lockCollection(ctx, tx, &g.CollectionID, user, false, check); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, query, g.CollectionID, grantee)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	if count, _ := result.RowsAffected(); count == 0 {
+		return tberrors.ErrGrantNotFound
+	}
+
+	e := model.NewAccessEvent(ctx, model.ActionRevokeGrant, g.CollectionID.String(), user, org)
+	e.SetGrantee(g)
+	err = insertEvent(ctx, tx, e)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	return nil
+}
+
+// ListGrants implements [datastore.TestBertDatastore].
+func (s *sqlStore) ListGrants(ctx context.Context, collectionID *uuid.UUID, user *uuid.UUID, org *uuid.UUID) ([]*model.Grant, error) {
+	query := `
+	SELECT collection_id, user_id, can_edit, can_share, granted_by, created_at
+	FROM collection_grants
+	WHERE collection_id = $1;`
+
+	out := []*model.Grant{}
+	err := s.db.SelectContext(ctx, &out, query, collectionID)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	query = `
+	SELECT collection_id, group_id, can_edit, can_share, granted_by, created_at
+	FROM collection_group_grants
+	WHERE collection_id = $1;`
+
+	groups := []*model.Grant{}
+	err = s.db.SelectContext(ctx, &groups, query, collectionID)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	out = append(out, groups...)
+	model.SortGrants(out)
+
+	return out, nil
+}
+
+// GetEffectiveGrant implements [datastore.TestBertDatastore].
+func (s *sqlStore) GetEffectiveGrant(ctx context.Context, collectionID *uuid.UUID, user *uuid.UUID) (*model.Grant, error) {
+	query := `
+	SELECT collection_id, user_id, bool_or(can_edit) AS can_edit, bool_or(can_share) AS can_share
+	FROM effective_grants
+	WHERE collection_id = $1
+	  AND user_id = $2
+	GROUP BY collection_id, user_id;`
+
+	out := &model.Grant{}
+	err := s.db.GetContext(ctx, out, query, collectionID, user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	return out, nil
+}
+
+// GetEffectiveGrants implements [datastore.TestBertDatastore].
+func (s *sqlStore) GetEffectiveGrants(ctx context.Context, collectionIDs []uuid.UUID, user *uuid.UUID) (map[uuid.UUID]*model.Grant, error) {
+	query := `
+	SELECT collection_id, user_id, bool_or(can_edit) AS can_edit, bool_or(can_share) AS can_share
+	FROM effective_grants
+	WHERE collection_id = ANY($1::UUID[])
+	  AND user_id = $2
+	GROUP BY collection_id, user_id;`
+
+	ids := make([]string, 0, len(collectionIDs))
+	for _, id := range collectionIDs {
+		ids = append(ids, id.String())
+	}
+
+	found := []*model.Grant{}
+	err := s.db.SelectContext(ctx, &found, query, pq.Array(ids), user)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	out := map[uuid.UUID]*model.Grant{}
+	for _, g := range found {
+		out[g.CollectionID] = g
+	}
+
+	return out, nil
+}
+
+// lockGrant combines the user's grants on a collection like GetEffectiveGrant, locking the grants and group
+// memberships it reads for the rest of tx so they can't be revoked before a change they allow is made
+func lockGrant(ctx context.Context, tx *sqlx.Tx, collectionID *uuid.UUID, user *uuid.UUID) (*model.Grant, error) {
+	queries := []string{`
+	SELECT can_edit, can_share
+	FROM collection_grants
+	WHERE collection_id = $1
+	  AND user_id = $2
+	FOR SHARE;`, `
+	SELECT gg.can_edit, gg.can_share
+	FROM collection_group_grants gg
+	JOIN group_members m ON m.group_id = gg.group_id
+	WHERE gg.collection_id = $1
+	  AND m.user_id = $2
+	FOR SHARE;`}
+
+	var out *model.Grant
+	for _, query := range queries {
+		found := []*model.Grant{}
+		err := tx.SelectContext(ctx, &found, query, collectionID, user)
+		if err != nil {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+
+		for _, g := range found {
+			if out == nil {
+				out = &model.Grant{CollectionID: *collectionID, UserID: *user}
+			}
+			out.CanEdit = out.CanEdit || g.CanEdit
+			out.CanShare = out.CanShare || g.CanShare
+		}
+	}
+
+	return out, nil
+}
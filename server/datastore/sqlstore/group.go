@@ -0,0 +1,254 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// CreateGroup implements [datastore.TestBertDatastore].
+func (s *sqlStore) CreateGroup(ctx context.Context, name string, user *uuid.UUID, org *uuid.UUID) (*model.Group, error) {
+	query := `
+	INSERT INTO groups(id, org_id, name, created_by)
+	VALUES($1, $2, $3, $4)
+	ON CONFLICT (org_id, name) DO NOTHING
+	RETURNING id, org_id, name, created_by, created_at;`
+
+	out := &model.Group{}
+	err := s.db.GetContext(ctx, out, query, uuid.New(), org, name, user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tberrors.ErrGroupExists
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	return out, nil
+}
+
+// ListGroups implements [datastore.TestBertDatastore].
+func (s *sqlStore) ListGroups(ctx context.Context, user *uuid.UUID, org *uuid.UUID) ([]*model.Group, error) {
+	query := `
+	SELECT id, org_id, name, created_by, created_at
+	FROM groups
+	WHERE org_id = $1
+	ORDER BY name;`
+
+	out := []*model.Group{}
+	err := s.db.SelectContext(ctx, &out, query, org)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// DeleteGroup implements [datastore.TestBertDatastore].
+func (s *sqlStore) DeleteGroup(ctx context.Context, id *uuid.UUID, user *uuid.UUID, org *uuid.UUID) error {
+	// Memberships and grants are removed by the cascade
+	query := `
+	DELETE
+	FROM groups
+	WHERE id = $1;`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	if count, _ := result.RowsAffected(); count == 0 {
+		return tberrors.ErrGroupNotFound
+	}
+
+	e := model.NewAccessEvent(ctx, model.ActionDeleteGroup, "", user, org)
+	e.GroupID = id
+	err = insertEvent(ctx, tx, e)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	return nil
+}
+
+// AddGroupMember implements [datastore.TestBertDatastore].
+func (s *sqlStore) AddGroupMember(ctx context.Context, id *uuid.UUID, member *uuid.UUID, user *uuid.UUID, org *uuid.UUID) (*model.GroupMember, error) {
+	query := `
+	INSERT INTO group_members(group_id, user_id, added_by)
+	VALUES($1, $2, $3)
+	ON CONFLICT (group_id, user_id) DO NOTHING
+	RETURNING group_id, user_id, added_by, created_at;`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	out := &model.GroupMember{}
+	err = tx.GetContext(ctx, out, query, id, member, user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Already a member, which is left as it is without an event
+			query = `
+			SELECT group_id, user_id, added_by, created_at
+			FROM group_members
+			WHERE group_id = $1
+			  AND user_id = $2;`
+
+			err = tx.GetContext(ctx, out, query, id, member)
+		}
+		if err != nil {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+
+		return out, nil
+	}
+
+	e := model.NewAccessEvent(ctx, model.ActionAddGroupMember, "", user, org)
+	e.GroupID = id
+	e.GranteeID = member
+	err = insertEvent(ctx, tx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// RemoveGroupMember implements [datastore.TestBertDatastore].
+func (s *sqlStore) RemoveGroupMember(ctx context.Context, id *uuid.UUID, member *uuid.UUID, user *uuid.UUID, org *uuid.UUID) error {
+	query := `
+	DELETE
+	FROM group_members
+	WHERE group_id = $1
+	  AND user_id = $2;`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx, query, id, member)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	if count, _ := result.RowsAffected(); count == 0 {
+		return tberrors.ErrMemberNotFound
+	}
+
+	e := model.NewAccessEvent(ctx, model.ActionRemoveGroupMember, "", user, org)
+	e.GroupID = id
+	e.GranteeID = member
+	err = insertEvent(ctx, tx, e)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	return nil
+}
+
+// ListGroupMembers implements [datastore.TestBertDatastore].
+func (s *sqlStore) ListGroupMembers(ctx context.Context, id *uuid.UUID, user *uuid.UUID, org *uuid.UUID) ([]*model.GroupMember, error) {
+	query := `
+	SELECT group_id, user_id, added_by, created_at
+	FROM group_members
+	WHERE group_id = $1
+	ORDER BY created_at, user_id;`
+
+	out := []*model.GroupMember{}
+	err := s.db.SelectContext(ctx, &out, query, id)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// GetGroup implements [datastore.TestBertDatastore].
+func (s *sqlStore) GetGroup(ctx context.Context, id *uuid.UUID) (*model.Group, error) {
+	query := `
+	SELECT id, org_id, name, created_by, created_at
+	FROM groups
+	WHERE id = $1;`
+
+	out := &model.Group{}
+	err := s.db.GetContext(ctx, out, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tberrors.ErrGroupNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	return out, nil
+}
+
+// lockGroup Locks a group in the org against being deleted until tx ends, groups in other orgs are not found
+func lockGroup(ctx context.Context, tx *sqlx.Tx, id *uuid.UUID, org *uuid.UUID) (*model.Group, error) {
+	query := `
+	SELECT id, org_id, name, created_by, created_at
+	FROM groups
+	WHERE id = $1
+	  AND org_id = $2
+	FOR SHARE;`
+
+	out := &model.Group{}
+	err := tx.GetContext(ctx, out, query, id, org)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tberrors.ErrGroupNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	return out, nil
+}
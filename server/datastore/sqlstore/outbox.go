@@ -0,0 +1,152 @@
+package sqlstore
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// relayLease How long a relay has to deliver the events it claimed before another relay may claim them
+const relayLease = time.Minute
+
+// RelayEvents implements [datastore.TestBertDatastore].
+func (s *sqlStore) RelayEvents(ctx context.Context, limit int, deliver func(*model.AccessEvent) error) (int, error) {
+	// The batch is leased and committed before delivery, so no locks are held while the publisher runs and
+	// concurrent relays skip the claimed events rather than sending them twice
+	query := `
+	UPDATE access_event_outbox
+	SET claimed_until = now() + make_interval(secs => $2)
+	WHERE id IN (
+		SELECT id
+		FROM access_event_outbox
+		WHERE sent_at IS NULL
+		  AND (claimed_until IS NULL OR claimed_until < now())
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED)
+	RETURNING id, action, collection_id, user_id, org_id, token_fingerprint, token_id, occurred_at,
+		trace_id, span_id, peer_address, user_agent, grpc_method, grantee_id, group_id, org_admin,
+		service_principal;`
+
+	deadline := time.Now().Add(relayLease)
+	pending := []*model.AccessEvent{}
+	err := s.db.SelectContext(ctx, &pending, query, limit, relayLease.Seconds())
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return 0, tberrors.ErrInternal
+	}
+	slices.SortFunc(pending, func(a, b *model.AccessEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	// stop once the lease runs out, as another relay may have claimed the rest by then
+	sent := []int64{}
+	var deliverErr error
+	for _, e := range pending {
+		if time.Now().After(deadline) {
+			break
+		}
+		if deliverErr = deliver(e); deliverErr != nil {
+			break
+		}
+		sent = append(sent, e.ID)
+	}
+
+	unsent := []int64{}
+	for _, e := range pending[len(sent):] {
+		unsent = append(unsent, e.ID)
+	}
+
+	// the events were delivered even if ctx ended while publishing, so record that regardless
+	if err = s.finishRelay(context.WithoutCancel(ctx), sent, unsent); err != nil {
+		return 0, err
+	}
+
+	return len(sent), deliverErr
+}
+
+// finishRelay marks the sent events and releases the claim on the unsent ones so the next relay retries them
+// straight away
+func (s *sqlStore) finishRelay(ctx context.Context, sent, unsent []int64) error {
+	if len(sent) == 0 && len(unsent) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+	UPDATE access_event_outbox
+	SET sent_at = now(), claimed_until = NULL
+	WHERE id = ANY($1);`, pq.Array(sent))
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	UPDATE access_event_outbox
+	SET claimed_until = NULL
+	WHERE id = ANY($1) AND sent_at IS NULL;`, pq.Array(unsent))
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	return nil
+}
+
+// PurgeSentEvents implements [datastore.TestBertDatastore].
+func (s *sqlStore) PurgeSentEvents(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+	DELETE FROM access_event_outbox
+	WHERE sent_at IS NOT NULL
+	  AND sent_at < $1;`
+
+	result, err := s.db.ExecContext(ctx, query, before)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return 0, tberrors.ErrInternal
+	}
+
+	count, _ := result.RowsAffected()
+	return count, nil
+}
+
+// insertEvent records e in the outbox and the audit log as part of tx
+func insertEvent(ctx context.Context, tx *sqlx.Tx, e *model.AccessEvent) error {
+	query := `
+	INSERT INTO access_event_outbox(action, collection_id, user_id, org_id, token_fingerprint, token_id, occurred_at,
+		trace_id, span_id, peer_address, user_agent, grpc_method, grantee_id, group_id, org_admin,
+		service_principal)
+	VALUES(:action, :collection_id, :user_id, :org_id, :token_fingerprint, :token_id, :occurred_at,
+		:trace_id, :span_id, :peer_address, :user_agent, :grpc_method, :grantee_id, :group_id, :org_admin,
+		:service_principal);`
+
+	_, err := tx.NamedExecContext(ctx, query, e)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	return insertAuditEvent(ctx, tx, e)
+}
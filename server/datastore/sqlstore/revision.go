@@ -0,0 +1,139 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"testbert/server/datastore"
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ListRevisions implements [datastore.TestBertDatastore].
+func (s *sqlStore) ListRevisions(ctx context.Context, collectionID *uuid.UUID, before int64, limit int, user *uuid.UUID, org *uuid.UUID) ([]*model.Revision, error) {
+	query := `
+	SELECT collection_id, revision, title, data, org_view, org_edit, org_share, user_id, org_id, created_at
+	FROM collection_revisions
+	WHERE collection_id = $1
+	  AND ($2::BIGINT = 0 OR revision < $2)
+	ORDER BY revision DESC
+	LIMIT $3;`
+
+	out := []*model.Revision{}
+	err := s.db.SelectContext(ctx, &out, query, collectionID, before, limit)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// GetRevision implements [datastore.TestBertDatastore].
+func (s *sqlStore) GetRevision(ctx context.Context, collectionID *uuid.UUID, revision int64, user *uuid.UUID, org *uuid.UUID) (*model.Revision, error) {
+	return getRevision(ctx, s.db, collectionID, revision)
+}
+
+// getRevision looks up a revision of a collection with q, which may be a transaction
+func getRevision(ctx context.Context, q sqlx.QueryerContext, collectionID *uuid.UUID, revision int64) (*model.Revision, error) {
+	query := `
+	SELECT collection_id, revision, title, data, org_view, org_edit, org_share, user_id, org_id, created_at
+	FROM collection_revisions
+	WHERE collection_id = $1
+	  AND revision = $2;`
+
+	out := &model.Revision{}
+	err := sqlx.GetContext(ctx, q, out, query, collectionID, revision)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tberrors.ErrRevisionNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	return out, nil
+}
+
+// RestoreRevision implements [datastore.TestBertDatastore].
+func (s *sqlStore) RestoreRevision(ctx context.Context, collectionID *uuid.UUID, revision int64, version int64, user *uuid.UUID, org *uuid.UUID, check datastore.CollectionCheck) (*model.Collection, error) {
+	query := `
+	UPDATE collections
+	SET title = $2,
+		data = $3,
+		org_view = $4,
+		org_edit = $5,
+		org_share = $6,
+		updated_at = now(),
+		last_modified_by = $7,
+		version = version + 1
+	WHERE id = $1
+	RETURNING id, user_id, org_id, title, data, data_size, org_view, org_edit, org_share,
+		created_at, updated_at, created_by, last_modified_by, version, deleted_at, deleted_by;`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	existing, err := lockCollection(ctx, tx, collectionID, user, false, check)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := getRevision(ctx, tx, collectionID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && existing.Version != version {
+		return nil, tberrors.ErrEtagMismatch
+	}
+
+	out := &model.Collection{}
+	err = tx.GetContext(ctx, out, query, collectionID, r.Title, r.Data, r.OrgView, r.OrgEdit, r.OrgShare, user)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	err = insertRevision(ctx, tx, model.NewRevision(out, *org))
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertEvent(ctx, tx, model.NewAccessEvent(ctx, model.ActionRestore, out.ID.String(), user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+func insertRevision(ctx context.Context, tx *sqlx.Tx, r *model.Revision) error {
+	query := `
+	INSERT INTO collection_revisions(collection_id, revision, title, data, org_view, org_edit, org_share, user_id, org_id, created_at)
+	VALUES(:collection_id, :revision, :title, :data, :org_view, :org_edit, :org_share, :user_id, :org_id, :created_at);`
+
+	_, err := tx.NamedExecContext(ctx, query, r)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	return nil
+}
@@ -1,66 +1,227 @@
 package sqlstore
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"log"
+	"time"
 
+	"testbert/server/datastore"
 	"testbert/server/model"
+	"testbert/server/sharetoken"
 	"testbert/server/tberrors"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 )
 
 // CreateSharingToken implements [datastore.TestBertDatastore].
-func (s *sqlStore) CreateSharingToken(collectionID *uuid.UUID, user *uuid.UUID, org *uuid.UUID) (*model.SharingToken, error) {
-	existing, err := s.GetCollection(collectionID, user, org)
-	if err != nil {
-		return nil, err
-	}
-
-	if existing.UserID != *user {
-		if !existing.OrgShare || existing.OrgID != *org {
-			return nil, tberrors.ErrUnauthorized
-		}
-	}
-
+func (s *sqlStore) CreateSharingToken(ctx context.Context, t *model.SharingToken, user *uuid.UUID, org *uuid.UUID, check datastore.CollectionCheck) (*model.SharingToken, error) {
+	token := sharetoken.NewToken()
 	out := &model.SharingToken{
-		Token:        uuid.NewString(),
-		CollectionID: *collectionID,
+		TokenID:      sharetoken.NewID(),
+		TokenHash:    s.hasher.Hash(token),
+		CollectionID: t.CollectionID,
 		UserID:       *user,
 		OrgID:        *org,
+		ExpiresAt:    t.ExpiresAt,
+		MaxUses:      t.MaxUses,
+		CreatedAt:    time.Now().UTC(),
 	}
 
 	query := `
-	INSERT INTO shared_tokens(token, collection_id, user_id, org_id)
-	VALUES(:token, :collection_id, :user_id, :org_id);`
+	INSERT INTO shared_tokens(token_id, token_hash, collection_id, user_id, org_id, expires_at, max_uses, created_at)
+	VALUES(:token_id, :token_hash, :collection_id, :user_id, :org_id, :expires_at, :max_uses, :created_at);`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = lockCollection(ctx, tx, &t.CollectionID, user, false, check)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.NamedExecContext(ctx, query, out)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
 
-	_, err = s.db.NamedExec(query, out)
+	e := model.NewAccessEvent(ctx, model.ActionShare, out.CollectionID.String(), user, org)
+	e.TokenID = out.TokenID
+	e.TokenFingerprint = sharetoken.FingerprintFromHash(out.TokenHash)
+	err = insertEvent(ctx, tx, e)
 	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
 		log.Printf("database error: %v", err)
 		return nil, tberrors.ErrInternal
 	}
 
+	out.Token = token
+	out.TokenHash = ""
 	return out, nil
 }
 
-// DeleteSharingToken implements [datastore.TestBertDatastore].
-func (s *sqlStore) DeleteSharingToken(t string, user *uuid.UUID, org *uuid.UUID) error {
+// GetSharingToken implements [datastore.TestBertDatastore].
+func (s *sqlStore) GetSharingToken(ctx context.Context, t string) (*model.SharingToken, error) {
+	return s.getSharingToken(ctx, "token_hash", s.hasher.Hash(t))
+}
+
+// GetSharingTokenByID implements [datastore.TestBertDatastore].
+func (s *sqlStore) GetSharingTokenByID(ctx context.Context, id string) (*model.SharingToken, error) {
+	return s.getSharingToken(ctx, "token_id", id)
+}
+
+// getSharingToken looks up the token whose column matches value
+func (s *sqlStore) getSharingToken(ctx context.Context, column string, value string) (*model.SharingToken, error) {
+	query := fmt.Sprintf(`
+	SELECT token_id, collection_id, user_id, org_id, expires_at, max_uses, use_count, created_at, last_accessed_at
+	FROM shared_tokens
+	WHERE %s = $1;`, column)
+
+	out := &model.SharingToken{}
+	err := s.db.GetContext(ctx, out, query, value)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tberrors.ErrTokenNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	return out, nil
+}
+
+// DeleteSharingTokenByID implements [datastore.TestBertDatastore].
+func (s *sqlStore) DeleteSharingTokenByID(ctx context.Context, id string, user *uuid.UUID, org *uuid.UUID, check datastore.CollectionCheck) error {
 	query := `
 	DELETE
-	FROM shared_tokens t
-	USING collections c
-	WHERE t.token = $1
-		AND t.collection_id = c.id
-	  AND (c.user_id = $2 OR (c.org_id = $3 AND c.org_share));`
+	FROM shared_tokens
+	WHERE token_id = $1
+	RETURNING token_id, COALESCE(token_hash, '') AS token_hash, collection_id;`
 
-	result, err := s.db.Exec(query, t, user, org)
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		log.Printf("database error: %v", err)
 		return tberrors.ErrInternal
 	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-	if count, _ := result.RowsAffected(); count == 0 {
+	var collectionID uuid.UUID
+	err = tx.GetContext(ctx, &collectionID, `
+	SELECT collection_id
+	FROM shared_tokens
+	WHERE token_id = $1;`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return tberrors.ErrTokenNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return tberrors.ErrInternal
+		}
+	}
+
+	_, err = lockCollection(ctx, tx, &collectionID, user, false, check)
+	if err == tberrors.ErrCollectionNotFound {
 		return tberrors.ErrTokenNotFound
+	} else if err != nil {
+		return err
+	}
+
+	deleted := model.SharingToken{}
+	err = tx.GetContext(ctx, &deleted, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return tberrors.ErrTokenNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return tberrors.ErrInternal
+		}
+	}
+
+	e := model.NewAccessEvent(ctx, model.ActionRevoke, deleted.CollectionID.String(), user, org)
+	e.TokenID = deleted.TokenID
+	e.TokenFingerprint = sharetoken.FingerprintFromHash(deleted.TokenHash)
+	err = insertEvent(ctx, tx, e)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
 	}
 
 	return nil
 }
+
+// ListSharingTokens implements [datastore.TestBertDatastore].
+func (s *sqlStore) ListSharingTokens(ctx context.Context, collectionID *uuid.UUID, user *uuid.UUID, org *uuid.UUID) ([]*model.SharingToken, error) {
+	query := `
+	SELECT token_id, collection_id, user_id, org_id, expires_at, max_uses, use_count, created_at, last_accessed_at
+	FROM shared_tokens
+	WHERE collection_id = $1
+	ORDER BY created_at, token_id;`
+
+	out := []*model.SharingToken{}
+	err := s.db.SelectContext(ctx, &out, query, collectionID)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// HashLegacySharingTokens replaces tokens stored in plain text by earlier versions with their hash
+func HashLegacySharingTokens(db *sqlx.DB, hasher *sharetoken.Hasher) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	legacy := []struct {
+		TokenID string `db:"token_id"`
+		Token   string `db:"token"`
+	}{}
+
+	err = tx.Select(&legacy, `
+	SELECT token_id, token
+	FROM shared_tokens
+	WHERE token IS NOT NULL
+	  AND token_hash IS NULL
+	FOR UPDATE;`)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range legacy {
+		_, err = tx.Exec(`
+		UPDATE shared_tokens
+		SET token_hash = $2, token = NULL
+		WHERE token_id = $1;`, t.TokenID, hasher.Hash(t.Token))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(legacy) > 0 {
+		log.Printf("hashed %d legacy sharing tokens", len(legacy))
+	}
+
+	return tx.Commit()
+}
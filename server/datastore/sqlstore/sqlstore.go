@@ -3,16 +3,22 @@ package sqlstore
 
 import (
 	"testbert/server/datastore"
+	"testbert/server/sharetoken"
 
 	"github.com/jmoiron/sqlx"
 )
 
 type sqlStore struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	hasher *sharetoken.Hasher
+	// apiKeyHasher Hashes API keys, with a different key to sharing tokens
+	apiKeyHasher *sharetoken.Hasher
 }
 
-func NewSQLStore(db *sqlx.DB) datastore.TestBertDatastore {
+func NewSQLStore(db *sqlx.DB, hasher, apiKeyHasher *sharetoken.Hasher) datastore.TestBertDatastore {
 	return &sqlStore{
-		db: db,
+		db:           db,
+		hasher:       hasher,
+		apiKeyHasher: apiKeyHasher,
 	}
 }
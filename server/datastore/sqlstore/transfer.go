@@ -0,0 +1,235 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"testbert/server/datastore"
+	"testbert/server/model"
+	"testbert/server/sharetoken"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// TransferOwnership implements [datastore.TestBertDatastore].
+func (s *sqlStore) TransferOwnership(ctx context.Context, t *model.Transfer, user *uuid.UUID, org *uuid.UUID, check datastore.CollectionCheck) (*model.Collection, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	existing, err := lockCollection(ctx, tx, &t.CollectionID, user, false, check)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.ToUserID == existing.UserID {
+		return nil, tberrors.ErrInvalidTransfer
+	}
+
+	offer := *t
+	if offer.ToOrgID == uuid.Nil {
+		offer.ToOrgID = existing.OrgID
+	}
+	t = &offer
+
+	var out *model.Collection
+	if t.MovesOrg(existing) {
+		err = offerTransfer(ctx, tx, existing, t, user, org)
+	} else {
+		out, err = completeTransfer(ctx, tx, existing, t, user, org)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// AcceptTransfer implements [datastore.TestBertDatastore].
+func (s *sqlStore) AcceptTransfer(ctx context.Context, collectionID *uuid.UUID, user *uuid.UUID, org *uuid.UUID) (*model.Collection, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	t := &model.Transfer{}
+	err = tx.GetContext(ctx, t, `
+	SELECT collection_id, from_user_id, to_user_id, to_org_id, offered_at
+	FROM collection_transfers
+	WHERE collection_id = $1
+	  AND to_user_id = $2
+	  AND to_org_id = $3
+	FOR UPDATE;`, collectionID, user, org)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tberrors.ErrTransferNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	// Read in the transaction so the offer and the collection are consistent
+	existing := &model.Collection{}
+	err = tx.GetContext(ctx, existing, `
+	SELECT id, user_id, org_id, title, data, data_size, org_view, org_edit, org_share,
+		created_at, updated_at, created_by, last_modified_by, version, deleted_at, deleted_by
+	FROM collections
+	WHERE id = $1
+	  AND deleted_at IS NULL;`, collectionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tberrors.ErrTransferNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	if existing.UserID != t.FromUserID {
+		return nil, tberrors.ErrTransferNotFound
+	}
+
+	out, err := completeTransfer(ctx, tx, existing, t, user, org)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// offerTransfer records the offer of c to t.ToUserID in t.ToOrgID, replacing any earlier offer
+func offerTransfer(ctx context.Context, tx *sqlx.Tx, c *model.Collection, t *model.Transfer, user *uuid.UUID, org *uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+	INSERT INTO collection_transfers(collection_id, from_user_id, to_user_id, to_org_id)
+	VALUES($1, $2, $3, $4)
+	ON CONFLICT (collection_id) DO UPDATE
+	SET from_user_id = EXCLUDED.from_user_id,
+		to_user_id = EXCLUDED.to_user_id,
+		to_org_id = EXCLUDED.to_org_id,
+		offered_at = now();`, c.ID, c.UserID, t.ToUserID, t.ToOrgID)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return tberrors.ErrInternal
+	}
+
+	e := model.NewAccessEvent(ctx, model.ActionTransferOffered, c.ID.String(), user, org)
+	e.GranteeID = &t.ToUserID
+	return insertEvent(ctx, tx, e)
+}
+
+// completeTransfer hands c to its new owner, revoking the sharing tokens and access that don't carry over. The
+// transfer has lapsed if c changed owner since it was offered
+func completeTransfer(ctx context.Context, tx *sqlx.Tx, c *model.Collection, t *model.Transfer, user *uuid.UUID, org *uuid.UUID) (*model.Collection, error) {
+	movesOrg := t.MovesOrg(c)
+
+	// Org permissions belong to the old org
+	out := &model.Collection{}
+	err := tx.GetContext(ctx, out, `
+	UPDATE collections
+	SET user_id = $2,
+		org_id = $3,
+		org_view = org_view AND NOT $4,
+		org_edit = org_edit AND NOT $4,
+		org_share = org_share AND NOT $4,
+		updated_at = now(),
+		last_modified_by = $5,
+		version = version + 1
+	WHERE id = $1
+	  AND user_id = $6
+	  AND deleted_at IS NULL
+	RETURNING id, user_id, org_id, title, data, data_size, org_view, org_edit, org_share,
+		created_at, updated_at, created_by, last_modified_by, version, deleted_at, deleted_by;`,
+		c.ID, t.ToUserID, t.ToOrgID, movesOrg, user, c.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, tberrors.ErrTransferNotFound
+		} else {
+			log.Printf("database error: %v", err)
+			return nil, tberrors.ErrInternal
+		}
+	}
+
+	// Same rule as [model.Transfer.RevokesToken]
+	revoked := []model.SharingToken{}
+	err = tx.SelectContext(ctx, &revoked, `
+	DELETE
+	FROM shared_tokens
+	WHERE collection_id = $1
+	  AND (user_id = $2 OR $3::BOOL)
+	RETURNING token_id, COALESCE(token_hash, '') AS token_hash;`, c.ID, c.UserID, movesOrg)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	for _, st := range revoked {
+		e := model.NewAccessEvent(ctx, model.ActionRevoke, c.ID.String(), user, org)
+		e.TokenID = st.TokenID
+		e.TokenFingerprint = sharetoken.FingerprintFromHash(st.TokenHash)
+		if err = insertEvent(ctx, tx, e); err != nil {
+			return nil, err
+		}
+	}
+
+	// Group grants belong to the old org and the new owner no longer needs a grant
+	_, err = tx.ExecContext(ctx, `
+	DELETE
+	FROM collection_group_grants
+	WHERE collection_id = $1
+	  AND $2::BOOL;`, c.ID, movesOrg)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	DELETE
+	FROM collection_grants
+	WHERE collection_id = $1
+	  AND user_id = $2;`, c.ID, t.ToUserID)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	DELETE
+	FROM collection_transfers
+	WHERE collection_id = $1;`, c.ID)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	e := model.NewAccessEvent(ctx, model.ActionTransfer, c.ID.String(), user, org)
+	e.GranteeID = &out.UserID
+	err = insertEvent(ctx, tx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
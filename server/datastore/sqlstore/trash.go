@@ -0,0 +1,124 @@
+package sqlstore
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"testbert/server/datastore"
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+)
+
+// ListTrash implements [datastore.TestBertDatastore].
+func (s *sqlStore) ListTrash(ctx context.Context, after *uuid.UUID, limit int, user *uuid.UUID, org *uuid.UUID) ([]*model.Collection, error) {
+	query := `
+	SELECT id, user_id, org_id, title, data, data_size, org_view, org_edit, org_share,
+		created_at, updated_at, created_by, last_modified_by, version, deleted_at, deleted_by
+	FROM collections
+	WHERE ($1::UUID IS NULL OR id > $1)
+	  AND deleted_at IS NOT NULL
+	  AND ` + related + `
+	ORDER BY id
+	LIMIT $4;`
+
+	cursor := uuid.NullUUID{}
+	if after != nil {
+		cursor = uuid.NullUUID{UUID: *after, Valid: true}
+	}
+
+	out := []*model.Collection{}
+	err := s.db.SelectContext(ctx, &out, query, cursor, user, org, limit)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// RestoreCollection implements [datastore.TestBertDatastore].
+func (s *sqlStore) RestoreCollection(ctx context.Context, id *uuid.UUID, user *uuid.UUID, org *uuid.UUID, check datastore.CollectionCheck) (*model.Collection, error) {
+	query := `
+	UPDATE collections
+	SET deleted_at = NULL,
+		deleted_by = NULL,
+		version = version + 1
+	WHERE id = $1
+	RETURNING id, user_id, org_id, title, data, data_size, org_view, org_edit, org_share,
+		created_at, updated_at, created_by, last_modified_by, version, deleted_at, deleted_by;`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = lockCollection(ctx, tx, id, user, true, check)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &model.Collection{}
+	err = tx.GetContext(ctx, out, query, id)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	err = insertEvent(ctx, tx, model.NewAccessEvent(ctx, model.ActionUndelete, out.ID.String(), user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return nil, tberrors.ErrInternal
+	}
+
+	return out, nil
+}
+
+// PurgeTrash implements [datastore.TestBertDatastore].
+func (s *sqlStore) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+	DELETE FROM collections
+	WHERE deleted_at IS NOT NULL
+	  AND deleted_at < $1
+	RETURNING id, org_id;`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return 0, tberrors.ErrInternal
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	purged := []*model.Collection{}
+	err = tx.SelectContext(ctx, &purged, query, before)
+	if err != nil {
+		log.Printf("database error: %v", err)
+		return 0, tberrors.ErrInternal
+	}
+
+	for _, c := range purged {
+		err = insertEvent(ctx, tx, model.NewAccessEvent(ctx, model.ActionPurge, c.ID.String(), nil, &c.OrgID))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("database error: %v", err)
+		return 0, tberrors.ErrInternal
+	}
+
+	return int64(len(purged)), nil
+}
@@ -0,0 +1,19 @@
+package datastore
+
+import (
+	"context"
+
+	"testbert/server/model"
+
+	"github.com/google/uuid"
+)
+
+type TransferStore interface {
+	// TransferOwnership makes t.ToUserID the owner of t.CollectionID if check allows it. The collection stays
+	// in its org when t.ToOrgID is uuid.Nil. When t.ToOrgID is another org the transfer is only offered,
+	// replacing any earlier offer, and nil is returned until the new owner accepts it
+	TransferOwnership(ctx context.Context, t *model.Transfer, user, org *uuid.UUID, check CollectionCheck) (*model.Collection, error)
+	// AcceptTransfer completes the transfer of a collection offered to the user in their org, the offer is
+	// what authorizes it
+	AcceptTransfer(ctx context.Context, collectionID, user, org *uuid.UUID) (*model.Collection, error)
+}
@@ -0,0 +1,23 @@
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"testbert/server/model"
+
+	"github.com/google/uuid"
+)
+
+type TrashStore interface {
+	// ListTrash returns up to limit deleted collections related to the user ordered by id, starting after the
+	// given id when it is not nil. The caller decides which the user can restore
+	ListTrash(ctx context.Context, after *uuid.UUID, limit int, user, org *uuid.UUID) ([]*model.Collection, error)
+	// GetCollectionIncludingTrash returns a collection whether or not it is in the trash, without checking
+	// access
+	GetCollectionIncludingTrash(ctx context.Context, id *uuid.UUID) (*model.Collection, error)
+	RestoreCollection(ctx context.Context, id, user, org *uuid.UUID, check CollectionCheck) (*model.Collection, error)
+	// PurgeTrash permanently removes collections deleted before the given time, recording a purge event for each,
+	// and returns how many were removed
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+}
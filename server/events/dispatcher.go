@@ -0,0 +1,388 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"testbert/server/interceptors/metrics"
+	"testbert/server/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// PolicyBlock waits up to the timeout for room in the queue, then drops the event
+	PolicyBlock = "block"
+	// PolicyDropOldest makes room by dropping the event that has waited longest
+	PolicyDropOldest = "drop-oldest"
+	// PolicyDropNewest drops the event being dispatched
+	PolicyDropNewest = "drop-newest"
+	// PolicySpill appends the event to a file that is replayed once the queue has drained
+	PolicySpill = "spill"
+)
+
+// spillRetry How often spilled events are checked for when no new events arrive
+const spillRetry = time.Second
+
+// defaultBlockTimeout How long PolicyBlock waits when no timeout is given
+const defaultBlockTimeout = 250 * time.Millisecond
+
+type DispatcherOptions struct {
+	// Policy What to do when the queue is full
+	Policy string
+	Size   int
+	// Timeout How long PolicyBlock waits, zero uses a short default
+	Timeout time.Duration
+	// SpillFile Where PolicySpill writes events that don't fit in the queue
+	SpillFile string
+}
+
+// Dispatcher Queues events for a publisher so callers never wait on the publisher itself
+type Dispatcher struct {
+	publisher EventPublisher
+	events    chan *model.AccessEvent
+	policy    string
+	timeout   time.Duration
+	spill     *spillFile
+	// registration The queue depth callback, removed on Close
+	registration metric.Registration
+	// done Closed to stop run, which closes stopped once it has returned
+	done    chan struct{}
+	stopped chan struct{}
+	// ctx Used by run to publish, cancelled when Close gives up waiting for it
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewDispatcher creates a Dispatcher and starts publishing queued events in the background
+func NewDispatcher(publisher EventPublisher, opts DispatcherOptions) (*Dispatcher, error) {
+	if opts.Size <= 0 {
+		return nil, fmt.Errorf("invalid event queue size %d", opts.Size)
+	}
+
+	d := &Dispatcher{
+		publisher: publisher,
+		events:    make(chan *model.AccessEvent, opts.Size),
+		policy:    opts.Policy,
+		timeout:   opts.Timeout,
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	if d.timeout <= 0 {
+		d.timeout = defaultBlockTimeout
+	}
+
+	switch opts.Policy {
+	case PolicyBlock, PolicyDropOldest, PolicyDropNewest:
+	case PolicySpill:
+		spill, err := openSpillFile(opts.SpillFile)
+		if err != nil {
+			return nil, err
+		}
+		d.spill = spill
+	default:
+		return nil, fmt.Errorf("unknown event queue policy %q", opts.Policy)
+	}
+
+	registration, err := metrics.ObserveEventQueueDepth(d.Len)
+	if err != nil {
+		d.cancel()
+		if d.spill != nil {
+			_ = d.spill.close()
+		}
+		return nil, err
+	}
+	d.registration = registration
+
+	go d.run()
+
+	return d, nil
+}
+
+// Dispatch queues e, applying the policy when the queue is full
+func (d *Dispatcher) Dispatch(ctx context.Context, e *model.AccessEvent) {
+	select {
+	case d.events <- e:
+		d.queued(ctx, e)
+		return
+	default:
+	}
+
+	switch d.policy {
+	case PolicyBlock:
+		timer := time.NewTimer(d.timeout)
+		defer timer.Stop()
+
+		select {
+		case d.events <- e:
+			d.queued(ctx, e)
+		case <-timer.C:
+			d.dropped(ctx, e)
+		case <-ctx.Done():
+			d.dropped(ctx, e)
+		}
+	case PolicyDropOldest:
+		for {
+			select {
+			case d.events <- e:
+				d.queued(ctx, e)
+				return
+			default:
+			}
+
+			// the consumer may have made room already, in which case there is nothing to drop
+			select {
+			case old := <-d.events:
+				d.dropped(ctx, old)
+			default:
+			}
+		}
+	case PolicyDropNewest:
+		d.dropped(ctx, e)
+	case PolicySpill:
+		if err := d.spill.write(e); err != nil {
+			log.Printf("error spilling %s event: %v", e.Action, err)
+			d.dropped(ctx, e)
+			return
+		}
+		d.queued(ctx, e)
+	}
+}
+
+// Len returns the number of events waiting to be published
+func (d *Dispatcher) Len() int64 {
+	n := int64(len(d.events))
+	if d.spill != nil {
+		n += d.spill.len()
+	}
+	return n
+}
+
+// Close stops publishing in the background, then publishes the events still queued until ctx is done. What
+// is left after that is spilled to disk by PolicySpill and dropped otherwise. Dispatch must not be called
+// after Close
+func (d *Dispatcher) Close(ctx context.Context) error {
+	if err := d.registration.Unregister(); err != nil {
+		log.Printf("error removing event queue depth callback: %v", err)
+	}
+
+	close(d.done)
+	select {
+	case <-d.stopped:
+	case <-ctx.Done():
+		// abandon the event being published rather than wait for the publisher to give up
+		d.cancel()
+		<-d.stopped
+	}
+	d.cancel()
+
+	for {
+		select {
+		case e := <-d.events:
+			d.flush(ctx, e)
+		default:
+			if d.spill != nil {
+				return d.spill.close()
+			}
+			return nil
+		}
+	}
+}
+
+func (d *Dispatcher) flush(ctx context.Context, e *model.AccessEvent) {
+	if ctx.Err() == nil {
+		d.publish(ctx, e)
+		return
+	}
+
+	if d.spill != nil {
+		err := d.spill.write(e)
+		if err == nil {
+			return
+		}
+		log.Printf("error spilling %s event: %v", e.Action, err)
+	}
+	d.dropped(ctx, e)
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.stopped)
+
+	var retry <-chan time.Time
+	if d.spill != nil {
+		ticker := time.NewTicker(spillRetry)
+		defer ticker.Stop()
+		retry = ticker.C
+	}
+
+	for {
+		select {
+		case <-d.done:
+			return
+		default:
+		}
+
+		// replay spilled events whenever the queue has caught up
+		if d.spill != nil && len(d.events) == 0 {
+			d.replaySpill()
+		}
+
+		select {
+		case e := <-d.events:
+			d.publish(d.ctx, e)
+		case <-retry:
+		case <-d.done:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) replaySpill() {
+	spilled, err := d.spill.drain()
+	if err != nil {
+		log.Printf("error reading spilled events: %v", err)
+	}
+
+	for i, e := range spilled {
+		select {
+		case <-d.done:
+			// keep the rest for the next run rather than hold up Close
+			for _, e := range spilled[i:] {
+				if err := d.spill.write(e); err != nil {
+					log.Printf("error spilling %s event: %v", e.Action, err)
+				}
+			}
+			return
+		default:
+		}
+
+		d.publish(d.ctx, e)
+	}
+}
+
+func (d *Dispatcher) publish(ctx context.Context, e *model.AccessEvent) {
+	// publish the event for counts, dashboards, and analysis
+	if err := d.publisher.Publish(ctx, e); err != nil {
+		log.Printf("error publishing %s event: %v", e.Action, err)
+	}
+}
+
+func (d *Dispatcher) queued(ctx context.Context, e *model.AccessEvent) {
+	metrics.EventsQueuedCount.Add(ctx, 1, d.attributes(e))
+}
+
+func (d *Dispatcher) dropped(ctx context.Context, e *model.AccessEvent) {
+	metrics.EventsDroppedCount.Add(ctx, 1, d.attributes(e))
+}
+
+func (d *Dispatcher) attributes(e *model.AccessEvent) metric.MeasurementOption {
+	return metric.WithAttributes(
+		attribute.String("event.action", e.Action),
+		attribute.String("event.policy", d.policy),
+	)
+}
+
+// spillFile Events written as newline delimited JSON, kept across restarts until they are replayed
+type spillFile struct {
+	file  *os.File
+	count int64
+	lock  sync.Mutex
+}
+
+func openSpillFile(path string) (*spillFile, error) {
+	if path == "" {
+		return nil, fmt.Errorf("event spill file not set")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o640)
+	if err != nil {
+		return nil, err
+	}
+
+	// events left over from a previous run are replayed too
+	var count int64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &spillFile{
+		file:  f,
+		count: count,
+	}, nil
+}
+
+func (s *spillFile) write(e *model.AccessEvent) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := json.NewEncoder(s.file).Encode(e); err != nil {
+		return err
+	}
+	s.count++
+	return nil
+}
+
+func (s *spillFile) len() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.count
+}
+
+// drain returns every spilled event and empties the file
+func (s *spillFile) drain() ([]*model.AccessEvent, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.count == 0 {
+		return nil, nil
+	}
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	out := []*model.AccessEvent{}
+	decoder := json.NewDecoder(s.file)
+	for {
+		e := &model.AccessEvent{}
+		err := decoder.Decode(e)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// keep what could be read, a partial line can only come from a crash mid write
+			log.Printf("error decoding spilled event: %v", err)
+			break
+		}
+		out = append(out, e)
+	}
+
+	// only forget the events once the file no longer holds them, otherwise they would be replayed twice
+	if err := s.file.Truncate(0); err != nil {
+		return nil, err
+	}
+	s.count = 0
+	return out, nil
+}
+
+func (s *spillFile) close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.file.Close()
+}
@@ -0,0 +1,272 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"testbert/server/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+// gatedPublisher Holds every Publish call until the gate is opened
+type gatedPublisher struct {
+	started chan struct{}
+	gate    chan struct{}
+	lock    sync.Mutex
+	events  []*model.AccessEvent
+}
+
+func newGatedPublisher() *gatedPublisher {
+	return &gatedPublisher{
+		started: make(chan struct{}, 100),
+		gate:    make(chan struct{}),
+	}
+}
+
+func (p *gatedPublisher) Publish(ctx context.Context, e *model.AccessEvent) error {
+	p.started <- struct{}{}
+	select {
+	case <-p.gate:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.events = append(p.events, e)
+	return nil
+}
+
+func (p *gatedPublisher) Close() error {
+	return nil
+}
+
+func (p *gatedPublisher) actions() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	out := []string{}
+	for _, e := range p.events {
+		out = append(out, e.Action)
+	}
+	return out
+}
+
+func TestDispatcher(t *testing.T) {
+	tests := []struct {
+		name string
+		opts DispatcherOptions
+		// ctx for the event dispatched while the queue is full
+		ctx         func() context.Context
+		want        []string
+		wantBlocked time.Duration
+	}{
+		{
+			name:        "block drops after timeout",
+			opts:        DispatcherOptions{Policy: PolicyBlock, Size: 1, Timeout: 50 * time.Millisecond},
+			want:        []string{"first", "second"},
+			wantBlocked: 50 * time.Millisecond,
+		},
+		{
+			name:        "block defaults the timeout",
+			opts:        DispatcherOptions{Policy: PolicyBlock, Size: 1},
+			want:        []string{"first", "second"},
+			wantBlocked: defaultBlockTimeout,
+		},
+		{
+			name: "block drops when the caller gives up",
+			opts: DispatcherOptions{Policy: PolicyBlock, Size: 1},
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			want: []string{"first", "second"},
+		},
+		{
+			name: "drop newest",
+			opts: DispatcherOptions{Policy: PolicyDropNewest, Size: 1},
+			want: []string{"first", "second"},
+		},
+		{
+			name: "drop oldest",
+			opts: DispatcherOptions{Policy: PolicyDropOldest, Size: 1},
+			want: []string{"first", "third"},
+		},
+		{
+			name: "spill",
+			opts: DispatcherOptions{Policy: PolicySpill, Size: 1, SpillFile: filepath.Join(t.TempDir(), "spill.ndjson")},
+			want: []string{"first", "second", "third"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := newGatedPublisher()
+			d, err := NewDispatcher(publisher, tt.opts)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			// the first event is taken off the queue and held by the publisher, the second fills the queue
+			d.Dispatch(context.Background(), newEvent("first"))
+			<-publisher.started
+			d.Dispatch(context.Background(), newEvent("second"))
+
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx()
+			}
+
+			start := time.Now()
+			d.Dispatch(ctx, newEvent("third"))
+			blocked := time.Since(start)
+
+			assert.GreaterOrEqual(t, blocked, tt.wantBlocked)
+			if tt.wantBlocked == 0 {
+				assert.Less(t, blocked, 50*time.Millisecond)
+			}
+
+			close(publisher.gate)
+			assert.Eventually(t, func() bool {
+				return len(publisher.actions()) == len(tt.want) && d.Len() == 0
+			}, 2*time.Second, 10*time.Millisecond)
+			assert.Equal(t, tt.want, publisher.actions())
+		})
+	}
+}
+
+func TestDispatcherReplaysSpillFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spill.ndjson")
+
+	leftover := newEvent("leftover")
+	b, err := json.Marshal(leftover)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, os.WriteFile(path, append(b, '\n'), 0o640))
+
+	publisher := newGatedPublisher()
+	close(publisher.gate)
+
+	d, err := NewDispatcher(publisher, DispatcherOptions{Policy: PolicySpill, Size: 1, SpillFile: path})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(publisher.actions()) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"leftover"}, publisher.actions())
+	assert.Equal(t, int64(0), d.Len())
+
+	info, err := os.Stat(path)
+	if assert.NoError(t, err) {
+		assert.Zero(t, info.Size())
+	}
+}
+
+func TestDispatcherClose(t *testing.T) {
+	tests := []struct {
+		name string
+		opts DispatcherOptions
+		// ctx for Close
+		ctx       func() context.Context
+		want      []string
+		wantSpill int
+	}{
+		{
+			name: "flushes the queue",
+			opts: DispatcherOptions{Policy: PolicyBlock, Size: 2},
+			want: []string{"first", "second", "third"},
+		},
+		{
+			name: "spills what can't be flushed",
+			opts: DispatcherOptions{Policy: PolicySpill, Size: 2, SpillFile: filepath.Join(t.TempDir(), "spill.ndjson")},
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			want:      []string{},
+			wantSpill: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+			publisher := newGatedPublisher()
+			d, err := NewDispatcher(publisher, tt.opts)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			// the first event is held by the publisher while the rest wait in the queue
+			d.Dispatch(context.Background(), newEvent("first"))
+			<-publisher.started
+			d.Dispatch(context.Background(), newEvent("second"))
+			d.Dispatch(context.Background(), newEvent("third"))
+
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx()
+			}
+
+			closed := make(chan error)
+			go func() {
+				closed <- d.Close(ctx)
+			}()
+			// the held event is let through once run has been told to stop, unless Close gives up on it
+			<-d.done
+			if tt.ctx == nil {
+				close(publisher.gate)
+			}
+			assert.NoError(t, <-closed)
+
+			assert.Equal(t, tt.want, publisher.actions())
+			if tt.opts.SpillFile != "" {
+				b, err := os.ReadFile(tt.opts.SpillFile)
+				if assert.NoError(t, err) {
+					assert.Equal(t, tt.wantSpill, bytes.Count(b, []byte("\n")))
+				}
+			}
+		})
+	}
+}
+
+func TestNewDispatcher(t *testing.T) {
+	tests := []struct {
+		name string
+		opts DispatcherOptions
+	}{
+		{
+			name: "unknown policy",
+			opts: DispatcherOptions{Policy: "drop-everything", Size: 1},
+		},
+		{
+			name: "empty queue",
+			opts: DispatcherOptions{Policy: PolicyBlock},
+		},
+		{
+			name: "spill without file",
+			opts: DispatcherOptions{Policy: PolicySpill, Size: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDispatcher(NopPublisher{}, tt.opts)
+			assert.Error(t, err)
+		})
+	}
+}
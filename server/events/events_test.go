@@ -0,0 +1,380 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	eventspb "testbert/protobuf/events"
+	"testbert/server/config"
+	"testbert/server/model"
+	"testbert/server/presenters"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func newEvent(action string) *model.AccessEvent {
+	user := uuid.New()
+	org := uuid.New()
+	return &model.AccessEvent{
+		CollectionID: uuid.NewString(),
+		User:         &user,
+		OrgID:        &org,
+		Action:       action,
+		OccurredAt:   time.Now().UTC().Truncate(time.Millisecond),
+		TraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:       "00f067aa0ba902b7",
+		PeerAddress:  "127.0.0.1:51234",
+		UserAgent:    "grpc-go/1.79.1",
+		Method:       "/collection.CollectionService/GetCollection",
+	}
+}
+
+func decodeEvent(t *testing.T, b []byte) *eventspb.AccessEvent {
+	out := &eventspb.AccessEvent{}
+	assert.NoError(t, protojson.Unmarshal(b, out))
+	return out
+}
+
+// assertPublished checks the published messages match the events in order
+func assertPublished(t *testing.T, want []*model.AccessEvent, got []*eventspb.AccessEvent) {
+	if !assert.Len(t, got, len(want)) {
+		return
+	}
+	for i := range want {
+		assert.True(t, proto.Equal(presenters.AccessEvent(want[i]), got[i]), "event %d: want %v got %v", i, want[i], got[i])
+	}
+}
+
+func TestNewPublisher(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.Configuration
+		want    EventPublisher
+		wantErr bool
+	}{
+		{
+			name: "none",
+			cfg:  &config.Configuration{EventSink: SinkNone},
+			want: NopPublisher{},
+		},
+		{
+			name: "file",
+			cfg:  &config.Configuration{EventSink: SinkFile, EventFile: filepath.Join(t.TempDir(), "events.ndjson")},
+			want: &FilePublisher{},
+		},
+		{
+			name: "webhook",
+			cfg:  &config.Configuration{EventSink: SinkWebhook, EventWebhookURL: "http://localhost/events"},
+			want: &WebhookPublisher{},
+		},
+		{
+			name:    "webhook without url",
+			cfg:     &config.Configuration{EventSink: SinkWebhook},
+			wantErr: true,
+		},
+		{
+			name: "queue",
+			cfg:  &config.Configuration{EventSink: SinkQueue, EventQueueAddr: "localhost:4222", EventQueueSubject: "testbert.access"},
+			want: &QueuePublisher{},
+		},
+		{
+			name:    "queue with invalid subject",
+			cfg:     &config.Configuration{EventSink: SinkQueue, EventQueueAddr: "localhost:4222", EventQueueSubject: "bad subject"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown",
+			cfg:     &config.Configuration{EventSink: "carrier-pigeon"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPublisher(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.IsType(t, tt.want, got)
+				assert.NoError(t, got.Close())
+			}
+		})
+	}
+}
+
+func TestMarshalEvent(t *testing.T) {
+	e := newEvent(model.ActionReadShared)
+	e.User = nil
+	e.OrgID = nil
+	e.TokenFingerprint = "0123456789abcdef"
+
+	b, err := marshalEvent(e)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the published schema keeps the proto field names and enum value names
+	assert.Contains(t, string(b), `"ACTION_READ_SHARED"`)
+	for _, field := range []string{"action", "occurred_at", "collection_id", "token_fingerprint", "trace_id", "span_id", "peer_address", "user_agent", "grpc_method"} {
+		assert.Contains(t, string(b), `"`+field+`"`)
+	}
+	assert.NotContains(t, string(b), `"user_id"`)
+	assert.NotContains(t, string(b), "\n")
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+
+	events := []*model.AccessEvent{newEvent("create"), newEvent("read"), newEvent("share")}
+	events[2].TokenFingerprint = "0123456789abcdef"
+
+	p, err := NewFilePublisher(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, e := range events[:2] {
+		assert.NoError(t, p.Publish(context.Background(), e))
+	}
+	assert.NoError(t, p.Close())
+
+	// reopening appends
+	p, err = NewFilePublisher(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, p.Publish(context.Background(), events[2]))
+	assert.NoError(t, p.Close())
+
+	f, err := os.Open(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	var got []*eventspb.AccessEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		got = append(got, decodeEvent(t, scanner.Bytes()))
+	}
+
+	assertPublished(t, events, got)
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		retries   int
+		wantCalls int32
+		wantErr   bool
+	}{
+		{
+			name:      "delivered first time",
+			statuses:  []int{http.StatusNoContent},
+			retries:   3,
+			wantCalls: 1,
+		},
+		{
+			name:      "retries server errors",
+			statuses:  []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK},
+			retries:   3,
+			wantCalls: 3,
+		},
+		{
+			name:      "gives up after retries",
+			statuses:  []int{http.StatusServiceUnavailable},
+			retries:   2,
+			wantCalls: 3,
+			wantErr:   true,
+		},
+		{
+			name:      "does not retry client errors",
+			statuses:  []int{http.StatusBadRequest},
+			retries:   3,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEvent("read")
+
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1)) - 1
+
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assertPublished(t, []*model.AccessEvent{e}, []*eventspb.AccessEvent{decodeEvent(t, body)})
+
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses)-1)])
+			}))
+			defer srv.Close()
+
+			p, err := NewWebhookPublisher(srv.URL, tt.retries)
+			if !assert.NoError(t, err) {
+				return
+			}
+			p.backoff = time.Millisecond
+			defer p.Close()
+
+			err = p.Publish(context.Background(), e)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCalls, calls.Load())
+		})
+	}
+}
+
+// queueStandIn Minimal NATS compatible server that records published messages
+type queueStandIn struct {
+	listener net.Listener
+	lock     sync.Mutex
+	messages map[string][][]byte
+	conns    []net.Conn
+}
+
+func newQueueStandIn(t *testing.T) *queueStandIn {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := &queueStandIn{
+		listener: l,
+		messages: map[string][][]byte{},
+	}
+	go q.serve()
+	t.Cleanup(func() { _ = l.Close() })
+
+	return q
+}
+
+func (q *queueStandIn) serve() {
+	for {
+		conn, err := q.listener.Accept()
+		if err != nil {
+			return
+		}
+		q.lock.Lock()
+		q.conns = append(q.conns, conn)
+		q.lock.Unlock()
+		go q.handle(conn)
+	}
+}
+
+func (q *queueStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+
+	_, _ = conn.Write([]byte(`INFO {"server_id":"stand-in","max_payload":1048576}` + "\r\n"))
+	reader := bufio.NewReader(conn)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "PING":
+			_, _ = conn.Write([]byte("PONG\r\n"))
+		case "PUB":
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			q.lock.Lock()
+			q.messages[fields[1]] = append(q.messages[fields[1]], payload[:size])
+			q.lock.Unlock()
+		}
+	}
+}
+
+// dropConnections closes every open client connection
+func (q *queueStandIn) dropConnections() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, c := range q.conns {
+		_ = c.Close()
+	}
+	q.conns = nil
+}
+
+func (q *queueStandIn) received(subject string) []*eventspb.AccessEvent {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	out := []*eventspb.AccessEvent{}
+	for _, m := range q.messages[subject] {
+		e := &eventspb.AccessEvent{}
+		if err := protojson.Unmarshal(m, e); err == nil {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestQueuePublisher(t *testing.T) {
+	q := newQueueStandIn(t)
+
+	p, err := NewQueuePublisher(q.listener.Addr().String(), "testbert.access")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer p.Close()
+
+	first := newEvent("create")
+	second := newEvent("readShared")
+
+	t.Run("publishes to subject", func(t *testing.T) {
+		assert.NoError(t, p.Publish(context.Background(), first))
+		assertPublished(t, []*model.AccessEvent{first}, q.received("testbert.access"))
+	})
+
+	t.Run("reconnects after the server drops the connection", func(t *testing.T) {
+		q.dropConnections()
+
+		assert.NoError(t, p.Publish(context.Background(), second))
+		assertPublished(t, []*model.AccessEvent{first, second}, q.received("testbert.access"))
+	})
+
+	t.Run("fails when the server is gone", func(t *testing.T) {
+		_ = q.listener.Close()
+		q.dropConnections()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		assert.Error(t, p.Publish(ctx, newEvent("read")))
+	})
+}
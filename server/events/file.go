@@ -0,0 +1,53 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+
+	"testbert/server/model"
+)
+
+// FilePublisher Appends events to a file as newline delimited JSON
+type FilePublisher struct {
+	file *os.File
+	lock sync.Mutex
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	if path == "" {
+		return nil, errors.New("event file not set")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilePublisher{
+		file: f,
+	}, nil
+}
+
+// Publish implements [EventPublisher].
+func (p *FilePublisher) Publish(_ context.Context, e *model.AccessEvent) error {
+	line, err := marshalEvent(e)
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	_, err = p.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close implements [EventPublisher].
+func (p *FilePublisher) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.file.Close()
+}
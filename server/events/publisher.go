@@ -0,0 +1,65 @@
+// Package events Delivery of access events to external sinks
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"testbert/server/config"
+	"testbert/server/model"
+	"testbert/server/presenters"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	SinkNone    = "none"
+	SinkFile    = "file"
+	SinkWebhook = "webhook"
+	SinkQueue   = "queue"
+)
+
+// eventJSON Events are published as the JSON form of the AccessEvent message in protobuf/events, keeping
+// the proto field names
+var eventJSON = protojson.MarshalOptions{UseProtoNames: true}
+
+// EventPublisher Delivers access events to a sink
+type EventPublisher interface {
+	// Publish delivers e, returning once the sink has accepted it
+	Publish(ctx context.Context, e *model.AccessEvent) error
+	Close() error
+}
+
+// NewPublisher creates the publisher selected by cfg.EventSink
+func NewPublisher(cfg *config.Configuration) (EventPublisher, error) {
+	switch cfg.EventSink {
+	case SinkNone, "":
+		return NopPublisher{}, nil
+	case SinkFile:
+		return NewFilePublisher(cfg.EventFile)
+	case SinkWebhook:
+		return NewWebhookPublisher(cfg.EventWebhookURL, cfg.EventWebhookRetries)
+	case SinkQueue:
+		return NewQueuePublisher(cfg.EventQueueAddr, cfg.EventQueueSubject)
+	default:
+		return nil, fmt.Errorf("unknown event sink %q", cfg.EventSink)
+	}
+}
+
+// NopPublisher Discards every event
+type NopPublisher struct{}
+
+// Publish implements [EventPublisher].
+func (NopPublisher) Publish(context.Context, *model.AccessEvent) error {
+	return nil
+}
+
+// Close implements [EventPublisher].
+func (NopPublisher) Close() error {
+	return nil
+}
+
+// marshalEvent encodes e in the published event schema
+func marshalEvent(e *model.AccessEvent) ([]byte, error) {
+	return eventJSON.Marshal(presenters.AccessEvent(e))
+}
@@ -0,0 +1,156 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"testbert/server/model"
+)
+
+const queueTimeout = 5 * time.Second
+
+// QueuePublisher Publishes events to a subject on a NATS compatible message queue, each publish is
+// followed by a PING so it only returns once the server has processed the message
+type QueuePublisher struct {
+	addr    string
+	subject string
+	conn    net.Conn
+	reader  *bufio.Reader
+	lock    sync.Mutex
+}
+
+// NewQueuePublisher creates a publisher for addr (host:port), the connection is made on first use
+func NewQueuePublisher(addr, subject string) (*QueuePublisher, error) {
+	if addr == "" {
+		return nil, errors.New("event queue address not set")
+	}
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return nil, fmt.Errorf("invalid event queue subject %q", subject)
+	}
+
+	return &QueuePublisher{
+		addr:    addr,
+		subject: subject,
+	}, nil
+}
+
+// Publish implements [EventPublisher].
+func (p *QueuePublisher) Publish(ctx context.Context, e *model.AccessEvent) error {
+	payload, err := marshalEvent(e)
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// a pooled connection may have been closed by the server, so retry once on a fresh one
+	reused := p.conn != nil
+	err = p.publish(ctx, payload)
+	if err != nil && reused {
+		err = p.publish(ctx, payload)
+	}
+
+	return err
+}
+
+func (p *QueuePublisher) publish(ctx context.Context, payload []byte) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	err := p.send(ctx, payload)
+	if err != nil {
+		_ = p.conn.Close()
+		p.conn = nil
+	}
+
+	return err
+}
+
+func (p *QueuePublisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: queueTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return err
+	}
+
+	_ = conn.SetDeadline(deadline(ctx))
+	reader := bufio.NewReader(conn)
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		_ = conn.Close()
+		return fmt.Errorf("unexpected event queue greeting %q", strings.TrimSpace(line))
+	}
+
+	_, err = conn.Write([]byte(`CONNECT {"verbose":false,"pedantic":false,"name":"testbert"}` + "\r\n"))
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	p.conn = conn
+	p.reader = reader
+	return nil
+}
+
+func (p *QueuePublisher) send(ctx context.Context, payload []byte) error {
+	_ = p.conn.SetDeadline(deadline(ctx))
+
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", p.subject, len(payload), payload)
+	if _, err := p.conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("event queue error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// Close implements [EventPublisher].
+func (p *QueuePublisher) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+func deadline(ctx context.Context) time.Time {
+	if d, ok := ctx.Deadline(); ok {
+		return d
+	}
+	return time.Now().Add(queueTimeout)
+}
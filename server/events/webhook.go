@@ -0,0 +1,96 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"testbert/server/model"
+)
+
+const (
+	webhookTimeout = 5 * time.Second
+	webhookBackoff = 250 * time.Millisecond
+)
+
+// WebhookPublisher POSTs each event as JSON, retrying on network errors and 429/5xx responses
+type WebhookPublisher struct {
+	url     string
+	retries int
+	backoff time.Duration
+	client  *http.Client
+}
+
+func NewWebhookPublisher(url string, retries int) (*WebhookPublisher, error) {
+	if url == "" {
+		return nil, errors.New("event webhook url not set")
+	}
+
+	return &WebhookPublisher{
+		url:     url,
+		retries: retries,
+		backoff: webhookBackoff,
+		client: &http.Client{
+			Timeout: webhookTimeout,
+		},
+	}, nil
+}
+
+// Publish implements [EventPublisher].
+func (p *WebhookPublisher) Publish(ctx context.Context, e *model.AccessEvent) error {
+	body, err := marshalEvent(e)
+	if err != nil {
+		return err
+	}
+
+	backoff := p.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := p.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= p.retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends one request, reporting whether a failure is worth retrying
+func (p *WebhookPublisher) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("event webhook returned %s", resp.Status)
+}
+
+// Close implements [EventPublisher].
+func (p *WebhookPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"testbert/server/config"
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type fakeAPIKeys map[string]*model.APIKey
+
+func (f fakeAPIKeys) UseAPIKey(_ context.Context, key string) (*model.APIKey, error) {
+	k, ok := f[key]
+	if !ok {
+		return nil, tberrors.ErrAPIKeyNotFound
+	}
+	return k, nil
+}
+
+func TestAPIKey(t *testing.T) {
+	k := &model.APIKey{ID: uuid.New(), OrgID: uuid.New()}
+	apiKeys := fakeAPIKeys{"good-key": k}
+	info := &grpc.UnaryServerInfo{FullMethod: "/collection.CollectionService/GetCollection"}
+
+	call := func(apiKeys APIKeys, key string) (context.Context, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", key))
+
+		var got context.Context
+		_, err := Interceptor(&config.Configuration{}, Secret("testkey"), nil, apiKeys)(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+			got = ctx
+			return nil, nil
+		})
+		return got, err
+	}
+
+	ctx, err := call(apiKeys, "good-key")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &k.ID, ctx.Value(config.KeyUserID))
+	assert.Equal(t, &k.OrgID, ctx.Value(config.KeyOrgID))
+	assert.Equal(t, "", ctx.Value(config.KeyRole))
+	assert.True(t, model.IsServicePrincipal(ctx))
+	assert.False(t, model.IsOrgAdmin(ctx))
+
+	_, err = call(apiKeys, "bad-key")
+	assert.Equal(t, tberrors.ErrUnauthorized, err)
+
+	_, err = call(nil, "good-key")
+	assert.Equal(t, tberrors.ErrUnauthorized, err, "keys not configured")
+}
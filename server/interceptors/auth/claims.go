@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Reasons a call fails authentication, reported in metrics and traces but never to the client
+const (
+	reasonMissingToken     = "missing_token"
+	reasonMalformed        = "malformed"
+	reasonAlgorithm        = "algorithm_not_allowed"
+	reasonUnknownKey       = "unknown_key"
+	reasonInvalidSignature = "invalid_signature"
+	reasonMissingExpiry    = "missing_expiry"
+	reasonExpired          = "expired"
+	reasonNotYetValid      = "not_yet_valid"
+	reasonInvalidIssuer    = "invalid_issuer"
+	reasonInvalidAudience  = "invalid_audience"
+	reasonMissingSubject   = "missing_subject"
+	reasonInvalidAPIKey    = "invalid_api_key"
+)
+
+// claimRules What the registered claims of a token must hold, times are compared allowing for leeway of clock skew
+type claimRules struct {
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+// check returns why claims aren't valid at now, or "" if they are
+func (r *claimRules) check(claims jwt.MapClaims, now time.Time) string {
+	exp, ok := numericDate(claims, "exp")
+	if !ok {
+		return reasonMissingExpiry
+	}
+	if !now.Before(exp.Add(r.leeway)) {
+		return reasonExpired
+	}
+
+	for _, name := range []string{"nbf", "iat"} {
+		if _, present := claims[name]; !present {
+			continue
+		}
+		t, ok := numericDate(claims, name)
+		if !ok {
+			return reasonMalformed
+		}
+		if now.Add(r.leeway).Before(t) {
+			return reasonNotYetValid
+		}
+	}
+
+	if r.issuer != "" {
+		iss, _ := claims["iss"].(string)
+		if iss != r.issuer {
+			return reasonInvalidIssuer
+		}
+	}
+
+	if r.audience != "" && !slices.Contains(audiences(claims), r.audience) {
+		return reasonInvalidAudience
+	}
+
+	return ""
+}
+
+// numericDate reads a NumericDate claim, seconds since the epoch that may have a fractional part
+func numericDate(claims jwt.MapClaims, name string) (time.Time, bool) {
+	var seconds float64
+	switch v := claims[name].(type) {
+	case float64:
+		seconds = v
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		seconds = f
+	default:
+		return time.Time{}, false
+	}
+
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return time.Time{}, false
+	}
+
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*float64(time.Second))), true
+}
+
+// audiences reads the aud claim, which is either a single audience or a list of them
+func audiences(claims jwt.MapClaims) []string {
+	switch v := claims["aud"].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		auds := make([]string, 0, len(v))
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				auds = append(auds, s)
+			}
+		}
+		return auds
+	default:
+		return nil
+	}
+}
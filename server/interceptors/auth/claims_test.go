@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"testbert/server/config"
+	"testbert/server/tberrors"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestClaimRules(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	rules := &claimRules{issuer: "testbert-authenticator", audience: "testbert", leeway: 30 * time.Second}
+
+	valid := func(change func(jwt.MapClaims)) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"iss": "testbert-authenticator",
+			"aud": "testbert",
+			"exp": float64(now.Add(time.Hour).Unix()),
+			"nbf": float64(now.Add(-time.Minute).Unix()),
+			"iat": float64(now.Add(-time.Minute).Unix()),
+		}
+		if change != nil {
+			change(claims)
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name   string
+		rules  *claimRules
+		claims jwt.MapClaims
+		want   string
+	}{
+		{
+			name:   "valid",
+			claims: valid(nil),
+		},
+		{
+			name:   "audience in list",
+			claims: valid(func(c jwt.MapClaims) { c["aud"] = []any{"other", "testbert"} }),
+		},
+		{
+			name:   "expired within leeway",
+			claims: valid(func(c jwt.MapClaims) { c["exp"] = float64(now.Add(-10 * time.Second).Unix()) }),
+		},
+		{
+			name:   "not before within leeway",
+			claims: valid(func(c jwt.MapClaims) { c["nbf"] = float64(now.Add(10 * time.Second).Unix()) }),
+		},
+		{
+			name:   "fractional expiry",
+			claims: valid(func(c jwt.MapClaims) { c["exp"] = float64(now.Unix()) + 0.5 }),
+		},
+		{
+			name:   "any issuer or audience when not configured",
+			rules:  &claimRules{},
+			claims: valid(func(c jwt.MapClaims) { delete(c, "iss"); delete(c, "aud") }),
+		},
+		{
+			name:   "missing expiry",
+			claims: valid(func(c jwt.MapClaims) { delete(c, "exp") }),
+			want:   reasonMissingExpiry,
+		},
+		{
+			name:   "expiry not a number",
+			claims: valid(func(c jwt.MapClaims) { c["exp"] = "tomorrow" }),
+			want:   reasonMissingExpiry,
+		},
+		{
+			name:   "expired",
+			claims: valid(func(c jwt.MapClaims) { c["exp"] = float64(now.Add(-time.Minute).Unix()) }),
+			want:   reasonExpired,
+		},
+		{
+			name:   "not before",
+			claims: valid(func(c jwt.MapClaims) { c["nbf"] = float64(now.Add(time.Minute).Unix()) }),
+			want:   reasonNotYetValid,
+		},
+		{
+			name:   "issued in the future",
+			claims: valid(func(c jwt.MapClaims) { c["iat"] = float64(now.Add(time.Minute).Unix()) }),
+			want:   reasonNotYetValid,
+		},
+		{
+			name:   "not before not a number",
+			claims: valid(func(c jwt.MapClaims) { c["nbf"] = true }),
+			want:   reasonMalformed,
+		},
+		{
+			name:   "wrong issuer",
+			claims: valid(func(c jwt.MapClaims) { c["iss"] = "someone-else" }),
+			want:   reasonInvalidIssuer,
+		},
+		{
+			name:   "missing issuer",
+			claims: valid(func(c jwt.MapClaims) { delete(c, "iss") }),
+			want:   reasonInvalidIssuer,
+		},
+		{
+			name:   "wrong audience",
+			claims: valid(func(c jwt.MapClaims) { c["aud"] = []any{"other"} }),
+			want:   reasonInvalidAudience,
+		},
+		{
+			name:   "missing audience",
+			claims: valid(func(c jwt.MapClaims) { delete(c, "aud") }),
+			want:   reasonInvalidAudience,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rules
+			if tt.rules != nil {
+				r = tt.rules
+			}
+			assert.Equal(t, tt.want, r.check(tt.claims, now))
+		})
+	}
+}
+
+func TestFailureReasons(t *testing.T) {
+	cfg := &config.Configuration{
+		AuthIssuer:   "testbert-authenticator",
+		AuthAudience: "testbert",
+		AuthLeeway:   time.Second,
+	}
+	keys := Secret("testkey")
+
+	signed := func(method jwt.SigningMethod, key any, change func(jwt.MapClaims)) string {
+		claims := jwt.MapClaims{
+			"iss":  "testbert-authenticator",
+			"aud":  "testbert",
+			"exp":  time.Now().Add(time.Hour).Unix(),
+			"user": uuid.NewString(),
+			"org":  uuid.NewString(),
+		}
+		if change != nil {
+			change(claims)
+		}
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		assert.NoError(t, err)
+		return "Bearer " + token
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{
+			name:   "valid",
+			header: signed(jwt.SigningMethodHS256, []byte("testkey"), nil),
+		},
+		{
+			name: "missing token",
+			want: reasonMissingToken,
+		},
+		{
+			name:   "malformed",
+			header: "Bearer not-a-jwt",
+			want:   reasonMalformed,
+		},
+		{
+			name:   "algorithm not allowed",
+			header: signed(jwt.SigningMethodHS512, []byte("testkey"), nil),
+			want:   reasonAlgorithm,
+		},
+		{
+			name:   "invalid signature",
+			header: signed(jwt.SigningMethodHS256, []byte("wrong"), nil),
+			want:   reasonInvalidSignature,
+		},
+		{
+			name:   "expired",
+			header: signed(jwt.SigningMethodHS256, []byte("testkey"), func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }),
+			want:   reasonExpired,
+		},
+		{
+			name:   "wrong audience",
+			header: signed(jwt.SigningMethodHS256, []byte("testkey"), func(c jwt.MapClaims) { c["aud"] = "other" }),
+			want:   reasonInvalidAudience,
+		},
+		{
+			name:   "missing user",
+			header: signed(jwt.SigningMethodHS256, []byte("testkey"), func(c jwt.MapClaims) { delete(c, "user") }),
+			want:   reasonMissingSubject,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			ctx, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "call")
+
+			md := metadata.MD{}
+			if tt.header != "" {
+				md.Set("authorization", tt.header)
+			}
+			ctx = metadata.NewIncomingContext(ctx, md)
+			info := &grpc.UnaryServerInfo{FullMethod: "/collection.CollectionService/GetCollection"}
+
+			_, err := Interceptor(cfg, keys, nil, nil)(ctx, nil, info, func(context.Context, any) (any, error) {
+				return nil, nil
+			})
+			span.End()
+
+			ended := recorder.Ended()
+			if !assert.Len(t, ended, 1) {
+				return
+			}
+			reason := ""
+			for _, attr := range ended[0].Attributes() {
+				if attr.Key == "auth.failure_reason" {
+					reason = attr.Value.AsString()
+				}
+			}
+			assert.Equal(t, tt.want, reason)
+
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			// the reason never reaches the client
+			assert.Equal(t, tberrors.ErrUnauthorized, err)
+		})
+	}
+}
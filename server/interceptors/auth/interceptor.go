@@ -3,76 +3,227 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"strings"
+	"time"
 
 	"testbert/server/config"
+	"testbert/server/interceptors/metrics"
+	"testbert/server/model"
 	"testbert/server/tberrors"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
-func Interceptor(cfg *config.Configuration) grpc.UnaryServerInterceptor {
-	key := []byte(cfg.AuthSecret)
-	keyFunc := func(_ *jwt.Token) (any, error) {
-		return key, nil
-	}
+// APIKeys Looks up the API keys machine clients authenticate with, see [datastore.APIKeyStore]
+type APIKeys interface {
+	UseAPIKey(ctx context.Context, key string) (*model.APIKey, error)
+}
+
+// Interceptor Authenticates calls with a bearer token signed with one of cfg.AuthAlgorithms, HS256 when none are set,
+// by a key in keys selected by the token's kid header. Tokens must not have expired and must come from cfg.AuthIssuer
+// for cfg.AuthAudience when they are set. Machine clients can instead send a key from apiKeys in the x-api-key
+// header to call as the key's service principal. Methods in public need neither.
+func Interceptor(cfg *config.Configuration, keys KeySet, public *PublicMethods, apiKeys APIKeys) grpc.UnaryServerInterceptor {
+	v := newVerifier(cfg, keys, public, apiKeys)
 
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		switch info.FullMethod {
-		case "/collection.CollectionService/GetSharedCollection":
-			// No auth required
-			return handler(ctx, req)
-		default:
-			ctx, ok := loadUserAndOrgOnContext(ctx, keyFunc)
-			if !ok {
-				return nil, tberrors.ErrUnauthorized
-			}
-
-			return handler(ctx, req)
+		ctx, err := v.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
 		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor Authenticates streams the same way [Interceptor] does calls, the handler's stream carries the
+// user and org on its context
+func StreamInterceptor(cfg *config.Configuration, keys KeySet, public *PublicMethods, apiKeys APIKeys) grpc.StreamServerInterceptor {
+	v := newVerifier(cfg, keys, public, apiKeys)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := v.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream Stream whose context has been enriched with the caller
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements [grpc.ServerStream].
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// reject records why the call failed authentication on its span and in metrics, the client only sees
+// [tberrors.ErrUnauthorized]
+func reject(ctx context.Context, method, reason string) error {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("auth.failure_reason", reason))
+	span.AddEvent("authentication failed", trace.WithAttributes(attribute.String("auth.failure_reason", reason)))
+
+	metrics.AuthFailureCount.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("grpc.method", method),
+		attribute.String("auth.failure_reason", reason),
+	))
+
+	return tberrors.ErrUnauthorized
+}
+
+type verifier struct {
+	parser  *jwt.Parser
+	keyFunc jwt.Keyfunc
+	rules   claimRules
+	public  *PublicMethods
+	apiKeys APIKeys
+}
+
+func newVerifier(cfg *config.Configuration, keys KeySet, public *PublicMethods, apiKeys APIKeys) *verifier {
+	algorithms := cfg.AuthAlgorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{jwt.SigningMethodHS256.Alg()}
+	}
+
+	return &verifier{
+		// the registered claims are checked by rules, allowing for clock skew
+		parser: &jwt.Parser{ValidMethods: algorithms, SkipClaimsValidation: true},
+		keyFunc: func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			return keys.Key(kid, token.Method.Alg())
+		},
+		rules: claimRules{
+			issuer:   cfg.AuthIssuer,
+			audience: cfg.AuthAudience,
+			leeway:   cfg.AuthLeeway,
+		},
+		public:  public,
+		apiKeys: apiKeys,
 	}
 }
 
-func loadUserAndOrgOnContext(ctx context.Context, keyFunc func(*jwt.Token) (any, error)) (context.Context, bool) {
+// authenticate returns ctx with the caller of method on it, public methods need no token
+func (v *verifier) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if v.public.IsPublic(method) {
+		// No auth required
+		return ctx, nil
+	}
+
+	if key := metadata.ValueFromIncomingContext(ctx, "x-api-key"); len(key) > 0 {
+		return v.loadServicePrincipalOnContext(ctx, method, key[0])
+	}
+
+	authCtx, reason := v.loadUserAndOrgOnContext(ctx)
+	if reason != "" {
+		return nil, reject(ctx, method, reason)
+	}
+
+	return authCtx, nil
+}
+
+// loadUserAndOrgOnContext verifies the call's token, returning why it isn't valid or "" with the user and org on ctx
+func (v *verifier) loadUserAndOrgOnContext(ctx context.Context) (context.Context, string) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return nil, false
+		return nil, reasonMissingToken
 	}
 
 	auth := md["authorization"]
 	if len(auth) < 1 {
-		return nil, false
+		return nil, reasonMissingToken
 	}
 
 	tokenString := strings.TrimPrefix(auth[0], "Bearer ")
 
-	token, err := jwt.Parse(tokenString, keyFunc)
+	token, err := v.parser.Parse(tokenString, v.keyFunc)
 	if err != nil {
-		return nil, false
+		return nil, v.parseFailure(token, err)
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return nil, false
+		return nil, reasonMalformed
+	}
+
+	if reason := v.rules.check(claims, time.Now()); reason != "" {
+		return nil, reason
 	}
 
 	userID := uuid.NullUUID{}
 	_ = userID.Scan(claims["user"])
 	if !userID.Valid {
-		return nil, false
+		return nil, reasonMissingSubject
 	}
 
 	orgID := uuid.NullUUID{}
 	_ = orgID.Scan(claims["org"])
 	if !orgID.Valid {
-		return nil, false
+		return nil, reasonMissingSubject
 	}
 
+	// The role is optional, anything other than a string is treated as no role
+	role, _ := claims["role"].(string)
+
 	ctx = context.WithValue(ctx, config.KeyUserID, &userID.UUID)
 	ctx = context.WithValue(ctx, config.KeyOrgID, &orgID.UUID)
+	ctx = context.WithValue(ctx, config.KeyRole, role)
+
+	return ctx, ""
+}
+
+// loadServicePrincipalOnContext returns ctx with the service principal of an API key on it, the principal's user
+// is the key's id
+func (v *verifier) loadServicePrincipalOnContext(ctx context.Context, method, key string) (context.Context, error) {
+	if v.apiKeys == nil {
+		return nil, reject(ctx, method, reasonInvalidAPIKey)
+	}
+
+	k, err := v.apiKeys.UseAPIKey(ctx, key)
+	if err == tberrors.ErrAPIKeyNotFound {
+		return nil, reject(ctx, method, reasonInvalidAPIKey)
+	} else if err != nil {
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("auth.api_key_id", k.ID.String()))
 
-	return ctx, true
+	ctx = context.WithValue(ctx, config.KeyUserID, &k.ID)
+	ctx = context.WithValue(ctx, config.KeyOrgID, &k.OrgID)
+	ctx = context.WithValue(ctx, config.KeyRole, "")
+	ctx = context.WithValue(ctx, config.KeyServicePrincipal, true)
+
+	return ctx, nil
+}
+
+// parseFailure classifies the error the parser rejected token with
+func (v *verifier) parseFailure(token *jwt.Token, err error) string {
+	var ve *jwt.ValidationError
+	if !errors.As(err, &ve) {
+		return reasonMalformed
+	}
+
+	switch {
+	case ve.Errors&jwt.ValidationErrorMalformed != 0:
+		return reasonMalformed
+	case ve.Errors&jwt.ValidationErrorUnverifiable != 0:
+		return reasonUnknownKey
+	case token != nil && token.Method != nil && !slices.Contains(v.parser.ValidMethods, token.Method.Alg()):
+		return reasonAlgorithm
+	default:
+		return reasonInvalidSignature
+	}
 }
@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	jwksTimeout = 5 * time.Second
+	// jwksMinRefresh Least time between refreshes triggered by tokens with an unknown kid
+	jwksMinRefresh = 30 * time.Second
+	jwksMaxSize    = 1 << 20
+)
+
+// JWKS Public keys loaded from a JSON Web Key Set file or http(s) URL, looked up by kid
+type JWKS struct {
+	source     string
+	client     *http.Client
+	minRefresh time.Duration
+
+	// refreshMu Serialises refreshes so an unknown kid only fetches the set once
+	refreshMu sync.Mutex
+
+	mu        sync.RWMutex
+	keys      map[string]*jwk
+	refreshed time.Time
+}
+
+type jwk struct {
+	alg string
+	key any
+}
+
+// jsonWebKey A key as it appears in the set, RFC 7517 and 7518
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// LoadJWKS reads the key set at source, failing if it can't be fetched or holds no usable keys
+func LoadJWKS(ctx context.Context, source string) (*JWKS, error) {
+	j := &JWKS{
+		source:     source,
+		minRefresh: jwksMinRefresh,
+		client: &http.Client{
+			Timeout: jwksTimeout,
+		},
+	}
+
+	err := j.Refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Start refreshes the key set every interval until ctx is done, keeping the current keys when a refresh fails
+func (j *JWKS) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			err := j.Refresh(ctx)
+			if err != nil {
+				log.Printf("error refreshing JWKS: %v", err)
+			}
+		}
+	}()
+}
+
+// Refresh replaces the keys with the ones currently at the source
+func (j *JWKS) Refresh(ctx context.Context) error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	return j.refresh(ctx)
+}
+
+func (j *JWKS) refresh(ctx context.Context) error {
+	body, err := j.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching JWKS %s: %w", j.source, err)
+	}
+
+	keys, err := parseJWKS(body)
+	if err != nil {
+		return fmt.Errorf("parsing JWKS %s: %w", j.source, err)
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.refreshed = time.Now()
+	j.mu.Unlock()
+
+	return nil
+}
+
+func (j *JWKS) fetch(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, jwksMaxSize))
+}
+
+// Key implements [KeySet]. A kid that isn't in the set triggers a refresh, at most once every minRefresh, so keys
+// the identity service rotates in are picked up before the next scheduled refresh.
+func (j *JWKS) Key(kid, alg string) (any, error) {
+	if kid == "" {
+		return nil, errKeyNotFound
+	}
+
+	k, ok := j.lookup(kid)
+	if !ok {
+		k, ok = j.refreshFor(kid)
+	}
+	if !ok || (k.alg != "" && k.alg != alg) || !keyFits(k.key, alg) {
+		return nil, errKeyNotFound
+	}
+
+	return k.key, nil
+}
+
+func (j *JWKS) lookup(kid string) (*jwk, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	k, ok := j.keys[kid]
+	return k, ok
+}
+
+func (j *JWKS) refreshFor(kid string) (*jwk, bool) {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	// Another request may have refreshed while this one waited
+	k, ok := j.lookup(kid)
+	if ok {
+		return k, true
+	}
+
+	j.mu.RLock()
+	recent := time.Since(j.refreshed) < j.minRefresh
+	j.mu.RUnlock()
+	if recent {
+		return nil, false
+	}
+
+	err := j.refresh(context.Background())
+	if err != nil {
+		log.Printf("error refreshing JWKS: %v", err)
+		return nil, false
+	}
+
+	return j.lookup(kid)
+}
+
+// keyFits checks key is the type alg verifies with, so a key can't be used with an algorithm from another family
+func keyFits(key any, alg string) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return strings.HasPrefix(alg, "ES")
+	default:
+		return false
+	}
+}
+
+// parseJWKS decodes a key set, skipping keys that aren't for signatures or whose type isn't supported
+func parseJWKS(body []byte) (map[string]*jwk, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	err := json.Unmarshal(body, &set)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*jwk, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kid == "" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			log.Printf("skipping JWKS key %q: %v", k.Kid, err)
+			continue
+		}
+
+		keys[k.Kid] = &jwk{alg: k.Alg, key: key}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no usable keys")
+	}
+
+	return keys, nil
+}
+
+func (k *jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent out of range")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("coordinates are the wrong length for the curve")
+		}
+
+		point := append([]byte{4}, x...)
+		point = append(point, y...)
+
+		return ecdsa.ParseUncompressedPublicKey(curve, point)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
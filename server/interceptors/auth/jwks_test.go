@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"testbert/server/config"
+	"testbert/server/tberrors"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// signingKey A private key and the kid it's published under
+type signingKey struct {
+	kid string
+	key any
+}
+
+func newRSAKey(t *testing.T, kid string) *signingKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return &signingKey{kid: kid, key: key}
+}
+
+func newECKey(t *testing.T, kid string) *signingKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	return &signingKey{kid: kid, key: key}
+}
+
+func encodeInt(i *big.Int, size int) string {
+	return base64.RawURLEncoding.EncodeToString(i.FillBytes(make([]byte, size)))
+}
+
+// encodeJWKS publishes the public halves of keys as a key set
+func encodeJWKS(t *testing.T, keys ...*signingKey) []byte {
+	set := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	for _, k := range keys {
+		switch key := k.key.(type) {
+		case *rsa.PrivateKey:
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kty: "RSA",
+				Kid: k.kid,
+				Use: "sig",
+				Alg: "RS256",
+				N:   encodeInt(key.N, key.Size()),
+				E:   encodeInt(big.NewInt(int64(key.E)), 3),
+			})
+		case *ecdsa.PrivateKey:
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kty: "EC",
+				Kid: k.kid,
+				Use: "sig",
+				Alg: "ES256",
+				Crv: "P-256",
+				X:   encodeInt(key.X, 32),
+				Y:   encodeInt(key.Y, 32),
+			})
+		}
+	}
+
+	b, err := json.Marshal(set)
+	assert.NoError(t, err)
+	return b
+}
+
+// jwksServer Serves a key set that tests can rotate
+type jwksServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	body  []byte
+	calls int
+}
+
+func newJWKSServer(t *testing.T, body []byte) *jwksServer {
+	s := &jwksServer{body: body}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(s.body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func (s *jwksServer) rotate(body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = body
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any) string {
+	token := jwt.NewWithClaims(method, jwt.MapClaims{
+		"exp":  time.Now().Add(time.Hour).Unix(),
+		"user": uuid.NewString(),
+		"org":  uuid.NewString(),
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return signed
+}
+
+// authenticate runs a call with token through the interceptor, returning the error it was rejected with
+func authenticate(cfg *config.Configuration, keys KeySet, token string) error {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	info := &grpc.UnaryServerInfo{FullMethod: "/collection.CollectionService/GetCollection"}
+
+	_, err := Interceptor(cfg, keys, nil, nil)(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+		if ctx.Value(config.KeyUserID) == nil || ctx.Value(config.KeyOrgID) == nil {
+			return nil, tberrors.ErrInternal
+		}
+		return nil, nil
+	})
+	return err
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa-1")
+	ecKey := newECKey(t, "ec-1")
+	other := newRSAKey(t, "rsa-1")
+	srv := newJWKSServer(t, encodeJWKS(t, rsaKey, ecKey))
+
+	keys, err := LoadJWKS(t.Context(), srv.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cfg := &config.Configuration{AuthAlgorithms: []string{"RS256", "ES256"}}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{
+			name:  "rs256",
+			token: sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey.key),
+		},
+		{
+			name:  "es256",
+			token: sign(t, jwt.SigningMethodES256, "ec-1", ecKey.key),
+		},
+		{
+			name:    "unknown kid",
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-2", rsaKey.key),
+			wantErr: tberrors.ErrUnauthorized,
+		},
+		{
+			name:    "missing kid",
+			token:   sign(t, jwt.SigningMethodRS256, "", rsaKey.key),
+			wantErr: tberrors.ErrUnauthorized,
+		},
+		{
+			name:    "wrong key for kid",
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-1", other.key),
+			wantErr: tberrors.ErrUnauthorized,
+		},
+		{
+			name:    "kid from another family",
+			token:   sign(t, jwt.SigningMethodES256, "rsa-1", ecKey.key),
+			wantErr: tberrors.ErrUnauthorized,
+		},
+		{
+			name:    "algorithm not allowed",
+			token:   sign(t, jwt.SigningMethodRS512, "rsa-1", rsaKey.key),
+			wantErr: tberrors.ErrUnauthorized,
+		},
+		{
+			name:    "hmac signed with the public key",
+			token:   sign(t, jwt.SigningMethodHS256, "rsa-1", encodeJWKS(t, rsaKey)),
+			wantErr: tberrors.ErrUnauthorized,
+		},
+		{
+			name:    "unsigned",
+			token:   sign(t, jwt.SigningMethodNone, "rsa-1", jwt.UnsafeAllowNoneSignatureType),
+			wantErr: tberrors.ErrUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, authenticate(cfg, keys, tt.token))
+		})
+	}
+}
+
+func TestJWKSRotation(t *testing.T) {
+	oldKey := newRSAKey(t, "old")
+	newKey := newECKey(t, "new")
+	srv := newJWKSServer(t, encodeJWKS(t, oldKey))
+
+	keys, err := LoadJWKS(t.Context(), srv.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	keys.minRefresh = 0
+
+	cfg := &config.Configuration{AuthAlgorithms: []string{"RS256", "ES256"}}
+	oldToken := sign(t, jwt.SigningMethodRS256, "old", oldKey.key)
+	newToken := sign(t, jwt.SigningMethodES256, "new", newKey.key)
+
+	assert.NoError(t, authenticate(cfg, keys, oldToken))
+
+	srv.rotate(encodeJWKS(t, newKey))
+	assert.NoError(t, keys.Refresh(t.Context()))
+
+	assert.NoError(t, authenticate(cfg, keys, newToken))
+	assert.Equal(t, tberrors.ErrUnauthorized, authenticate(cfg, keys, oldToken), "retired key still accepted")
+}
+
+func TestJWKSUnknownKidRefresh(t *testing.T) {
+	oldKey := newRSAKey(t, "old")
+	newKey := newRSAKey(t, "new")
+	srv := newJWKSServer(t, encodeJWKS(t, oldKey))
+
+	keys, err := LoadJWKS(t.Context(), srv.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cfg := &config.Configuration{AuthAlgorithms: []string{"RS256"}}
+	newToken := sign(t, jwt.SigningMethodRS256, "new", newKey.key)
+
+	srv.rotate(encodeJWKS(t, oldKey, newKey))
+
+	// The set was just loaded, so the unknown kid waits for the next refresh
+	assert.Equal(t, tberrors.ErrUnauthorized, authenticate(cfg, keys, newToken))
+	assert.Equal(t, 1, srv.requests())
+
+	keys.minRefresh = 0
+	assert.NoError(t, authenticate(cfg, keys, newToken))
+	assert.Equal(t, 2, srv.requests())
+}
+
+func TestJWKSFile(t *testing.T) {
+	key := newECKey(t, "ec-1")
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, encodeJWKS(t, key), 0o600))
+
+	keys, err := NewKeySet(t.Context(), &config.Configuration{AuthJWKS: path, AuthJWKSRefresh: time.Minute})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cfg := &config.Configuration{AuthAlgorithms: []string{"ES256"}}
+	assert.NoError(t, authenticate(cfg, keys, sign(t, jwt.SigningMethodES256, "ec-1", key.key)))
+}
+
+func TestLoadJWKSErrors(t *testing.T) {
+	unusable := newJWKSServer(t, []byte(`{"keys":[{"kty":"oct","kid":"k","k":"c2VjcmV0"},{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"}]}`))
+	missing := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(missing.Close)
+
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "no usable keys", source: unusable.URL},
+		{name: "not found", source: missing.URL},
+		{name: "missing file", source: filepath.Join(t.TempDir(), "jwks.json")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadJWKS(t.Context(), tt.source)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSecret(t *testing.T) {
+	cfg := &config.Configuration{}
+	keys := Secret("testkey")
+
+	assert.NoError(t, authenticate(cfg, keys, sign(t, jwt.SigningMethodHS256, "", []byte("testkey"))))
+	assert.Equal(t, tberrors.ErrUnauthorized, authenticate(cfg, keys, sign(t, jwt.SigningMethodHS256, "", []byte("wrong"))))
+	assert.Equal(t, tberrors.ErrUnauthorized, authenticate(cfg, keys, sign(t, jwt.SigningMethodHS512, "", []byte("testkey"))), "unpinned algorithm accepted")
+}
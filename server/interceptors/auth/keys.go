@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"testbert/server/config"
+)
+
+var errKeyNotFound = errors.New("no key for token")
+
+// KeySet Keys tokens are verified with
+type KeySet interface {
+	// Key returns the key identified by kid, provided it may verify tokens signed with alg
+	Key(kid, alg string) (any, error)
+}
+
+// Secret Shared HMAC secret, used for every token whatever its kid
+type Secret []byte
+
+// Key implements [KeySet].
+func (s Secret) Key(_, alg string) (any, error) {
+	if !strings.HasPrefix(alg, "HS") {
+		return nil, errKeyNotFound
+	}
+
+	return []byte(s), nil
+}
+
+// NewKeySet creates the key set selected by the configuration, a JWKS refreshed in the background until ctx is done
+// when cfg.AuthJWKS is set otherwise the shared secret
+func NewKeySet(ctx context.Context, cfg *config.Configuration) (KeySet, error) {
+	if cfg.AuthJWKS == "" {
+		return Secret(cfg.AuthSecret), nil
+	}
+
+	jwks, err := LoadJWKS(ctx, cfg.AuthJWKS)
+	if err != nil {
+		return nil, err
+	}
+	jwks.Start(ctx, cfg.AuthJWKSRefresh)
+
+	return jwks, nil
+}
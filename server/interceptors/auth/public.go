@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"fmt"
+
+	"testbert/protobuf/options"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// PublicMethods Methods callable without a token, those marked with the (testbert.auth).public option. Every other
+// method requires a token, including all of them until the methods are loaded.
+type PublicMethods struct {
+	methods map[string]bool
+}
+
+func NewPublicMethods() *PublicMethods {
+	return &PublicMethods{methods: map[string]bool{}}
+}
+
+// Load reads the options of the methods registered on srv from their descriptors, failing if any method has no
+// descriptor so its options can't be known. It must be called after the services are registered and before srv
+// starts serving.
+func (p *PublicMethods) Load(srv *grpc.Server) error {
+	methods := map[string]bool{}
+	for name, info := range srv.GetServiceInfo() {
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return fmt.Errorf("unknown service %s: %w", name, err)
+		}
+		service, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return fmt.Errorf("unknown service %s: not a service", name)
+		}
+
+		for _, m := range info.Methods {
+			method := service.Methods().ByName(protoreflect.Name(m.Name))
+			if method == nil {
+				return fmt.Errorf("unknown method /%s/%s", name, m.Name)
+			}
+
+			auth, _ := proto.GetExtension(method.Options(), options.E_Auth).(*options.AuthOptions)
+			if auth.GetPublic() {
+				methods[fmt.Sprintf("/%s/%s", name, m.Name)] = true
+			}
+		}
+	}
+
+	p.methods = methods
+	return nil
+}
+
+// IsPublic reports whether fullMethod can be called without a token
+func (p *PublicMethods) IsPublic(fullMethod string) bool {
+	return p != nil && p.methods[fullMethod]
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"testbert/protobuf/collection"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestPublicMethods(t *testing.T) {
+	srv := grpc.NewServer()
+	collection.RegisterCollectionServiceServer(srv, collection.UnimplementedCollectionServiceServer{})
+
+	public := NewPublicMethods()
+	assert.False(t, public.IsPublic("/collection.CollectionService/GetSharedCollection"), "public before loading")
+
+	if !assert.NoError(t, public.Load(srv)) {
+		return
+	}
+
+	assert.True(t, public.IsPublic("/collection.CollectionService/GetSharedCollection"))
+	assert.False(t, public.IsPublic("/collection.CollectionService/GetCollection"))
+	assert.False(t, public.IsPublic("/collection.CollectionService/Unknown"))
+
+	var none *PublicMethods
+	assert.False(t, none.IsPublic("/collection.CollectionService/GetSharedCollection"))
+}
+
+func TestPublicMethodsUnknown(t *testing.T) {
+	tests := []struct {
+		name string
+		desc *grpc.ServiceDesc
+	}{
+		{
+			name: "service without descriptor",
+			desc: &whoAmI,
+		},
+		{
+			name: "method without descriptor",
+			desc: &grpc.ServiceDesc{
+				ServiceName: "collection.CollectionService",
+				HandlerType: (*any)(nil),
+				Methods: []grpc.MethodDesc{
+					{
+						MethodName: "Unknown",
+						Handler: func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+							return nil, nil
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := grpc.NewServer()
+			srv.RegisterService(tt.desc, struct{}{})
+
+			assert.Error(t, NewPublicMethods().Load(srv))
+		})
+	}
+}
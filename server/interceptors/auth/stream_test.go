@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"testbert/server/config"
+	"testbert/server/interceptors/metrics"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// whoAmI Streams back the user and org the handler sees on its stream's context
+var whoAmI = grpc.ServiceDesc{
+	ServiceName: "test.Identity",
+	HandlerType: (*any)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WhoAmI",
+			ServerStreams: true,
+			Handler: func(_ any, stream grpc.ServerStream) error {
+				user, _ := stream.Context().Value(config.KeyUserID).(*uuid.UUID)
+				org, _ := stream.Context().Value(config.KeyOrgID).(*uuid.UUID)
+				if user == nil || org == nil {
+					return status.Error(codes.Internal, "caller not on context")
+				}
+
+				if err := stream.SendMsg(wrapperspb.String(user.String())); err != nil {
+					return err
+				}
+				return stream.SendMsg(wrapperspb.String(org.String()))
+			},
+		},
+	},
+}
+
+func newStreamServer(t *testing.T, cfg *config.Configuration, keys KeySet) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+
+	srv := grpc.NewServer(grpc.ChainStreamInterceptor(
+		metrics.StreamServerInterceptor(),
+		StreamInterceptor(cfg, keys, nil, nil),
+	))
+	srv.RegisterService(&whoAmI, struct{}{})
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough://bufnet", grpc.WithContextDialer(func(_ context.Context, _ string) (net.Conn, error) {
+		return lis.Dial()
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+// whoAmICall opens the stream with token, returning what the handler saw
+func whoAmICall(conn *grpc.ClientConn, token string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+
+	stream, err := conn.NewStream(ctx, &whoAmI.Streams[0], "/test.Identity/WhoAmI")
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var got []string
+	for {
+		msg := &wrapperspb.StringValue{}
+		if err := stream.RecvMsg(msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return got, nil
+			}
+			return got, err
+		}
+		got = append(got, msg.Value)
+	}
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	conn := newStreamServer(t, &config.Configuration{}, Secret("testkey"))
+
+	user := uuid.New()
+	org := uuid.New()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"exp":  time.Now().Add(time.Hour).Unix(),
+		"user": user.String(),
+		"org":  org.String(),
+	}).SignedString([]byte("testkey"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	got, err := whoAmICall(conn, token)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{user.String(), org.String()}, got)
+
+	_, err = whoAmICall(conn, "")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"exp":  time.Now().Add(-time.Hour).Unix(),
+		"user": user.String(),
+		"org":  org.String(),
+	}).SignedString([]byte("testkey"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = whoAmICall(conn, expired)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
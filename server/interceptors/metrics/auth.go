@@ -0,0 +1,18 @@
+package metrics
+
+import (
+	"go.opentelemetry.io/otel/metric"
+)
+
+// AuthFailureCount Calls rejected as unauthenticated, by reason
+var AuthFailureCount metric.Int64Counter
+
+func init() {
+	var err error
+	if AuthFailureCount, err = meter.Int64Counter(
+		"auth.failures_total",
+		metric.WithDescription("Total number of requests that failed authentication"),
+	); err != nil {
+		panic(err)
+	}
+}
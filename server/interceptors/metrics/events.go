@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	EventsQueuedCount  metric.Int64Counter
+	EventsDroppedCount metric.Int64Counter
+	eventQueueDepth    metric.Int64ObservableGauge
+)
+
+func init() {
+	var err error
+	if EventsQueuedCount, err = meter.Int64Counter(
+		"events.queued_total",
+		metric.WithDescription("Total number of access events queued for publishing"),
+	); err != nil {
+		panic(err)
+	}
+
+	if EventsDroppedCount, err = meter.Int64Counter(
+		"events.dropped_total",
+		metric.WithDescription("Total number of access events dropped before publishing"),
+	); err != nil {
+		panic(err)
+	}
+
+	if eventQueueDepth, err = meter.Int64ObservableGauge(
+		"events.queue_depth",
+		metric.WithDescription("Number of access events waiting to be published"),
+	); err != nil {
+		panic(err)
+	}
+}
+
+// ObserveEventQueueDepth reports depth as the event queue depth until the registration is unregistered
+func ObserveEventQueueDepth(depth func() int64) (metric.Registration, error) {
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(eventQueueDepth, depth())
+		return nil
+	}, eventQueueDepth)
+}
@@ -26,7 +26,25 @@ var (
 		tberrors.ErrUnauthorized,
 		tberrors.ErrCollectionNotFound,
 		tberrors.ErrTokenNotFound,
+		tberrors.ErrRevisionNotFound,
 		tberrors.ErrRateLimited,
+		tberrors.ErrInvalidPageToken,
+		tberrors.ErrInvalidPageSize,
+		tberrors.ErrEtagMismatch,
+		tberrors.ErrInvalidUpdateMask,
+		tberrors.ErrInvalidExpiry,
+		tberrors.ErrInvalidMaxUses,
+		tberrors.ErrInvalidAuditFilter,
+		tberrors.ErrGrantNotFound,
+		tberrors.ErrInvalidGrantee,
+		tberrors.ErrGroupNotFound,
+		tberrors.ErrMemberNotFound,
+		tberrors.ErrInvalidGroupName,
+		tberrors.ErrGroupExists,
+		tberrors.ErrInvalidTransfer,
+		tberrors.ErrTransferNotFound,
+		tberrors.ErrAPIKeyNotFound,
+		tberrors.ErrInvalidAPIKeyName,
 	}
 )
 
@@ -66,27 +84,45 @@ func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		startTime := time.Now()
 
-		attrs := []attribute.KeyValue{
-			attribute.String("grpc.method", info.FullMethod),
-		}
-
 		resp, err := handler(ctx, req)
 
-		duration := time.Since(startTime).Seconds()
-		statusCode := codes.OK
-		if err != nil {
-			statusCode = status.Code(err)
-		}
+		record(ctx, info.FullMethod, startTime, err)
+
+		return resp, err
+	}
+}
 
-		attrs = append(attrs, attribute.String("grpc.status_code", statusCode.String()))
+// StreamServerInterceptor Records streams like [UnaryServerInterceptor] does calls, the duration covering the whole
+// stream
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		startTime := time.Now()
 
-		requestCount.Add(ctx, 1, metric.WithAttributes(attrs...))
-		requestLatency.Record(ctx, duration, metric.WithAttributes(attrs...))
+		err := handler(srv, ss)
 
-		if err != nil && !slices.Contains(expectedErrors, err) {
-			errorCount.Add(ctx, 1, metric.WithAttributes(attrs...))
-		}
+		record(ss.Context(), info.FullMethod, startTime, err)
 
-		return resp, err
+		return err
+	}
+}
+
+func record(ctx context.Context, method string, startTime time.Time, err error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("grpc.method", method),
+	}
+
+	duration := time.Since(startTime).Seconds()
+	statusCode := codes.OK
+	if err != nil {
+		statusCode = status.Code(err)
+	}
+
+	attrs = append(attrs, attribute.String("grpc.status_code", statusCode.String()))
+
+	requestCount.Add(ctx, 1, metric.WithAttributes(attrs...))
+	requestLatency.Record(ctx, duration, metric.WithAttributes(attrs...))
+
+	if err != nil && !slices.Contains(expectedErrors, err) {
+		errorCount.Add(ctx, 1, metric.WithAttributes(attrs...))
 	}
 }
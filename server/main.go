@@ -11,11 +11,15 @@ import (
 	"time"
 
 	"testbert/protobuf/collection"
+	"testbert/server/authz"
 	"testbert/server/config"
 	"testbert/server/datastore/sqlstore"
+	"testbert/server/events"
 	"testbert/server/interceptors/auth"
 	"testbert/server/interceptors/metrics"
+	"testbert/server/redact"
 	"testbert/server/server"
+	"testbert/server/sharetoken"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -41,7 +45,9 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	tracerProvider, err := newTracerProvider(ctx, cfg)
+	hasher := sharetoken.NewHasher(cfg.ShareTokenSecret)
+
+	tracerProvider, err := newTracerProvider(ctx, cfg, hasher)
 	if err != nil {
 		log.Fatalf("failed to create tracer provider: %v", err)
 	}
@@ -58,22 +64,76 @@ func main() {
 	}
 	defer db.Close()
 
+	err = sqlstore.HashLegacySharingTokens(db, hasher)
+	if err != nil {
+		log.Fatalf("error hashing sharing tokens: %v", err)
+	}
+
+	store := sqlstore.NewSQLStore(db, hasher, sharetoken.NewHasher(cfg.APIKeySecret))
+
+	keys, err := auth.NewKeySet(ctx, cfg)
+	if err != nil {
+		log.Fatalf("error loading auth keys: %v", err)
+	}
+
+	public := auth.NewPublicMethods()
 	srv := grpc.NewServer(
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
 		grpc.ChainUnaryInterceptor(
 			metrics.UnaryServerInterceptor(),
-			auth.Interceptor(cfg),
+			auth.Interceptor(cfg, keys, public, store),
+		),
+		grpc.ChainStreamInterceptor(
+			metrics.StreamServerInterceptor(),
+			auth.StreamInterceptor(cfg, keys, public, store),
 		))
 
-	collection.RegisterCollectionServiceServer(srv, server.NewCollectionServer(sqlstore.NewSQLStore(db), cfg.AuthSecret))
+	publisher, err := events.NewPublisher(cfg)
+	if err != nil {
+		log.Fatalf("error creating event publisher: %v", err)
+	}
+	defer publisher.Close()
+
+	dispatcher, err := events.NewDispatcher(publisher, events.DispatcherOptions{
+		Policy:    cfg.EventBufferPolicy,
+		Size:      cfg.EventBufferSize,
+		Timeout:   cfg.EventBufferTimeout,
+		SpillFile: cfg.EventSpillFile,
+	})
+	if err != nil {
+		log.Fatalf("error creating event dispatcher: %v", err)
+	}
+	// closed before the publisher, which is deferred earlier, so queued events can still be published
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := dispatcher.Close(ctx); err != nil {
+			log.Printf("error closing event dispatcher: %v", err)
+		}
+	}()
+
+	collection.RegisterCollectionServiceServer(srv, server.NewCollectionServer(store, authz.Default{}, cfg.AuthSecret, hasher, dispatcher))
+
+	err = public.Load(srv)
+	if err != nil {
+		log.Fatalf("error loading public methods: %v", err)
+	}
+
+	purged := server.StartPurger(ctx, store, cfg.TrashRetention, cfg.EventOutboxRetention, cfg.TrashPurgeInterval)
+	relayed := server.StartEventRelay(ctx, store, publisher, cfg.EventRelayInterval)
 
 	err = listenAndServe(cfg, srv)
 	if err != nil {
 		log.Fatalf("server error: %v", err)
 	}
+
+	// the workers use the store and publisher, so they must stop before the deferred closes run
+	cancel()
+	<-purged
+	<-relayed
 }
 
-func newTracerProvider(ctx context.Context, cfg *config.Configuration) (*sdktrace.TracerProvider, error) {
+func newTracerProvider(ctx context.Context, cfg *config.Configuration, hasher *sharetoken.Hasher) (*sdktrace.TracerProvider, error) {
 	exporter, err := otlptracegrpc.New(ctx,
 		otlptracegrpc.WithEndpoint(fmt.Sprintf("%s:%s", cfg.OtlpEndpoint, cfg.OtlpPort)),
 		otlptracegrpc.WithInsecure(),
@@ -94,7 +154,7 @@ func newTracerProvider(ctx context.Context, cfg *config.Configuration) (*sdktrac
 	}
 
 	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
+		sdktrace.WithBatcher(redact.NewExporter(exporter, redact.NewRedactor(cfg.TraceRedactKeys, hasher.Fingerprint))),
 		sdktrace.WithResource(res),
 	)
 	return tp, nil
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE collections
+  ADD COLUMN title TEXT NOT NULL DEFAULT '',
+  ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN created_by UUID,
+  ADD COLUMN last_modified_by UUID,
+  ADD COLUMN data_size BIGINT GENERATED ALWAYS AS (COALESCE(octet_length(data), 0)) STORED;
+
+UPDATE collections SET created_by = user_id, last_modified_by = user_id;
+
+ALTER TABLE collections
+  ALTER COLUMN created_by SET NOT NULL,
+  ALTER COLUMN last_modified_by SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE collections
+  DROP COLUMN IF EXISTS title,
+  DROP COLUMN IF EXISTS created_at,
+  DROP COLUMN IF EXISTS updated_at,
+  DROP COLUMN IF EXISTS created_by,
+  DROP COLUMN IF EXISTS last_modified_by,
+  DROP COLUMN IF EXISTS data_size;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS collection_revisions (
+  collection_id UUID NOT NULL REFERENCES collections ON DELETE CASCADE,
+  revision BIGINT NOT NULL,
+  title TEXT NOT NULL,
+  data TEXT,
+  org_view BOOL NOT NULL,
+  org_edit BOOL NOT NULL,
+  org_share BOOL NOT NULL,
+  user_id UUID NOT NULL,
+  org_id UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (collection_id, revision)
+);
+
+INSERT INTO collection_revisions(collection_id, revision, title, data, org_view, org_edit, org_share, user_id, org_id, created_at)
+SELECT id, version, title, data, COALESCE(org_view, FALSE), COALESCE(org_edit, FALSE), COALESCE(org_share, FALSE),
+  last_modified_by, org_id, updated_at
+FROM collections;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS collection_revisions;
+-- +goose StatementEnd
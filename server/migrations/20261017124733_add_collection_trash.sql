@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE collections
+  ADD COLUMN deleted_at TIMESTAMPTZ,
+  ADD COLUMN deleted_by UUID;
+
+CREATE INDEX IF NOT EXISTS collections_deleted_at_idx ON collections (deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS collections_deleted_at_idx;
+
+ALTER TABLE collections
+  DROP COLUMN IF EXISTS deleted_at,
+  DROP COLUMN IF EXISTS deleted_by;
+-- +goose StatementEnd
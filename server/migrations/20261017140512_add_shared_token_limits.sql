@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shared_tokens
+  ADD COLUMN expires_at TIMESTAMPTZ,
+  ADD COLUMN max_uses BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN use_count BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shared_tokens
+  DROP COLUMN IF EXISTS expires_at,
+  DROP COLUMN IF EXISTS max_uses,
+  DROP COLUMN IF EXISTS use_count;
+-- +goose StatementEnd
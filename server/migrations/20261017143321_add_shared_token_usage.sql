@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shared_tokens
+  ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN last_accessed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS shared_tokens_collection_id_idx ON shared_tokens (collection_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS shared_tokens_collection_id_idx;
+
+ALTER TABLE shared_tokens
+  DROP COLUMN IF EXISTS created_at,
+  DROP COLUMN IF EXISTS last_accessed_at;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Raw tokens can't be hashed here without the secret, so existing rows keep their token until the server
+-- hashes them on startup (see sqlstore.HashLegacySharingTokens)
+ALTER TABLE shared_tokens
+  ADD COLUMN token_id TEXT,
+  ADD COLUMN token_hash TEXT;
+
+UPDATE shared_tokens SET token_id = substr(md5(random()::TEXT || token), 1, 16);
+
+ALTER TABLE shared_tokens
+  DROP CONSTRAINT shared_tokens_pkey,
+  ALTER COLUMN token DROP NOT NULL,
+  ALTER COLUMN token_id SET NOT NULL,
+  ADD PRIMARY KEY (token_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS shared_tokens_token_hash_idx ON shared_tokens (token_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Hashed tokens can't be recovered
+DELETE FROM shared_tokens WHERE token IS NULL;
+
+DROP INDEX IF EXISTS shared_tokens_token_hash_idx;
+
+ALTER TABLE shared_tokens
+  DROP CONSTRAINT shared_tokens_pkey,
+  ALTER COLUMN token SET NOT NULL,
+  ADD PRIMARY KEY (token),
+  DROP COLUMN IF EXISTS token_id,
+  DROP COLUMN IF EXISTS token_hash;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS access_event_outbox (
+  id BIGSERIAL PRIMARY KEY,
+  action TEXT NOT NULL,
+  collection_id TEXT NOT NULL DEFAULT '',
+  user_id UUID,
+  org_id UUID,
+  token_fingerprint TEXT NOT NULL DEFAULT '',
+  token_id TEXT NOT NULL DEFAULT '',
+  occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS access_event_outbox_unsent_idx ON access_event_outbox (id) WHERE sent_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS access_event_outbox;
+-- +goose StatementEnd
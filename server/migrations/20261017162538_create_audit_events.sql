@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS audit_events (
+  id BIGSERIAL PRIMARY KEY,
+  action TEXT NOT NULL,
+  collection_id TEXT NOT NULL DEFAULT '',
+  user_id UUID,
+  org_id UUID,
+  token_fingerprint TEXT NOT NULL DEFAULT '',
+  token_id TEXT NOT NULL DEFAULT '',
+  occurred_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_collection_idx ON audit_events (collection_id, id DESC);
+
+-- changes recorded before the audit log existed are still in the outbox
+INSERT INTO audit_events(action, collection_id, user_id, org_id, token_fingerprint, token_id, occurred_at)
+SELECT action, collection_id, user_id, org_id, token_fingerprint, token_id, occurred_at
+FROM access_event_outbox
+ORDER BY id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_events;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE access_event_outbox
+  ADD COLUMN IF NOT EXISTS trace_id TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS span_id TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS peer_address TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS grpc_method TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE audit_events
+  ADD COLUMN IF NOT EXISTS trace_id TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS span_id TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS peer_address TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS grpc_method TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_events
+  DROP COLUMN IF EXISTS trace_id,
+  DROP COLUMN IF EXISTS span_id,
+  DROP COLUMN IF EXISTS peer_address,
+  DROP COLUMN IF EXISTS user_agent,
+  DROP COLUMN IF EXISTS grpc_method;
+
+ALTER TABLE access_event_outbox
+  DROP COLUMN IF EXISTS trace_id,
+  DROP COLUMN IF EXISTS span_id,
+  DROP COLUMN IF EXISTS peer_address,
+  DROP COLUMN IF EXISTS user_agent,
+  DROP COLUMN IF EXISTS grpc_method;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS collection_grants (
+  collection_id UUID NOT NULL REFERENCES collections ON DELETE CASCADE,
+  user_id UUID NOT NULL,
+  can_edit BOOL NOT NULL DEFAULT FALSE,
+  can_share BOOL NOT NULL DEFAULT FALSE,
+  granted_by UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (collection_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS collection_grants_user_idx ON collection_grants (user_id);
+
+ALTER TABLE access_event_outbox ADD COLUMN IF NOT EXISTS grantee_id UUID;
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS grantee_id UUID;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_events DROP COLUMN IF EXISTS grantee_id;
+ALTER TABLE access_event_outbox DROP COLUMN IF EXISTS grantee_id;
+
+DROP TABLE IF EXISTS collection_grants;
+-- +goose StatementEnd
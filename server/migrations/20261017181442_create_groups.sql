@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS groups (
+  id UUID PRIMARY KEY,
+  org_id UUID NOT NULL,
+  name TEXT NOT NULL,
+  created_by UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (org_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS group_members (
+  group_id UUID NOT NULL REFERENCES groups ON DELETE CASCADE,
+  user_id UUID NOT NULL,
+  added_by UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS group_members_user_idx ON group_members (user_id);
+
+CREATE TABLE IF NOT EXISTS collection_group_grants (
+  collection_id UUID NOT NULL REFERENCES collections ON DELETE CASCADE,
+  group_id UUID NOT NULL REFERENCES groups ON DELETE CASCADE,
+  can_edit BOOL NOT NULL DEFAULT FALSE,
+  can_share BOOL NOT NULL DEFAULT FALSE,
+  granted_by UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (collection_id, group_id)
+);
+
+CREATE INDEX IF NOT EXISTS collection_group_grants_group_idx ON collection_group_grants (group_id);
+
+-- Every grant a user holds on a collection, directly or through a group. A user can appear more than once
+-- for the same collection
+CREATE OR REPLACE VIEW effective_grants AS
+SELECT collection_id, user_id, can_edit, can_share
+FROM collection_grants
+UNION ALL
+SELECT gg.collection_id, m.user_id, gg.can_edit, gg.can_share
+FROM collection_group_grants gg
+JOIN group_members m ON m.group_id = gg.group_id;
+
+ALTER TABLE access_event_outbox ADD COLUMN IF NOT EXISTS group_id UUID;
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS group_id UUID;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_events DROP COLUMN IF EXISTS group_id;
+ALTER TABLE access_event_outbox DROP COLUMN IF EXISTS group_id;
+
+DROP VIEW IF EXISTS effective_grants;
+DROP TABLE IF EXISTS collection_group_grants;
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS groups;
+-- +goose StatementEnd
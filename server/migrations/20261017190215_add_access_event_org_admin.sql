@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE access_event_outbox ADD COLUMN IF NOT EXISTS org_admin BOOL NOT NULL DEFAULT FALSE;
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS org_admin BOOL NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_events DROP COLUMN IF EXISTS org_admin;
+ALTER TABLE access_event_outbox DROP COLUMN IF EXISTS org_admin;
+-- +goose StatementEnd
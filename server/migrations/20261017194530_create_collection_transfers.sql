@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS collection_transfers (
+  collection_id UUID PRIMARY KEY REFERENCES collections ON DELETE CASCADE,
+  from_user_id UUID NOT NULL,
+  to_user_id UUID NOT NULL,
+  to_org_id UUID NOT NULL,
+  offered_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS collection_transfers_to_user_idx ON collection_transfers (to_user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS collection_transfers;
+-- +goose StatementEnd
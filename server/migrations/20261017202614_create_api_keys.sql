@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_keys (
+  id UUID PRIMARY KEY,
+  org_id UUID NOT NULL,
+  name TEXT NOT NULL,
+  key_hash TEXT NOT NULL UNIQUE,
+  created_by UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_org_idx ON api_keys (org_id, created_at);
+
+ALTER TABLE access_event_outbox ADD COLUMN IF NOT EXISTS service_principal BOOL NOT NULL DEFAULT FALSE;
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS service_principal BOOL NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_events DROP COLUMN IF EXISTS service_principal;
+ALTER TABLE access_event_outbox DROP COLUMN IF EXISTS service_principal;
+
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
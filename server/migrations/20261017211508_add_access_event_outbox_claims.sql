@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE access_event_outbox ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE access_event_outbox DROP COLUMN IF EXISTS claimed_until;
+-- +goose StatementEnd
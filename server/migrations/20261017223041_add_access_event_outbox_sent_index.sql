@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS access_event_outbox_sent_idx ON access_event_outbox (sent_at) WHERE sent_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS access_event_outbox_sent_idx;
+-- +goose StatementEnd
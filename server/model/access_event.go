@@ -0,0 +1,133 @@
+package model
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	ActionCreate          = "create"
+	ActionRead            = "read"
+	ActionUpdate          = "update"
+	ActionDelete          = "delete"
+	ActionShare           = "share"
+	ActionReadShared      = "readShared"
+	ActionRevoke          = "revoke"
+	ActionReadRevision    = "readRevision"
+	ActionRestore         = "restore"
+	ActionUndelete        = "undelete"
+	ActionGrant           = "grant"
+	ActionRevokeGrant     = "revokeGrant"
+	ActionTransfer        = "transfer"
+	ActionTransferOffered = "transferOffered"
+	// ActionPurge The collection was removed from the trash for good, by the purger rather than a user
+	ActionPurge = "purge"
+	// Group actions have no collection, GroupID is the group
+	ActionDeleteGroup       = "deleteGroup"
+	ActionAddGroupMember    = "addGroupMember"
+	ActionRemoveGroupMember = "removeGroupMember"
+)
+
+var actions = []string{
+	ActionCreate,
+	ActionRead,
+	ActionUpdate,
+	ActionDelete,
+	ActionShare,
+	ActionReadShared,
+	ActionRevoke,
+	ActionReadRevision,
+	ActionRestore,
+	ActionUndelete,
+	ActionGrant,
+	ActionRevokeGrant,
+	ActionTransfer,
+	ActionTransferOffered,
+	ActionPurge,
+	ActionDeleteGroup,
+	ActionAddGroupMember,
+	ActionRemoveGroupMember,
+}
+
+// IsAction reports whether action is one of the known actions
+func IsAction(action string) bool {
+	return slices.Contains(actions, action)
+}
+
+// AccessEvent Record of an action taken on a collection or sharing token
+type AccessEvent struct {
+	// ID Sequence number in the outbox or audit log the event was read from, consumers of the outbox can use
+	// it to discard events delivered more than once
+	ID int64 `db:"id" json:"id,omitempty"`
+	// TokenFingerprint Identifies the sharing token without revealing it, see [sharetoken.Hasher.Fingerprint]
+	TokenFingerprint string     `db:"token_fingerprint" json:"token_fingerprint,omitempty"`
+	TokenID          string     `db:"token_id" json:"token_id,omitempty"`
+	CollectionID     string     `db:"collection_id" json:"collection_id,omitempty"`
+	User             *uuid.UUID `db:"user_id" json:"user_id,omitempty"`
+	OrgID            *uuid.UUID `db:"org_id" json:"org_id,omitempty"`
+	// GranteeID The user given or losing access by a grant or revokeGrant, the new owner for a transfer or
+	// transferOffered, or the member for addGroupMember or removeGroupMember
+	GranteeID *uuid.UUID `db:"grantee_id" json:"grantee_id,omitempty"`
+	// GroupID The group given or losing access by a grant or revokeGrant, or changed by a group action
+	GroupID    *uuid.UUID `db:"group_id" json:"group_id,omitempty"`
+	Action     string     `db:"action" json:"action"`
+	OccurredAt time.Time  `db:"occurred_at" json:"occurred_at"`
+	// TraceID and SpanID Span of the request that caused the event, empty when it wasn't traced
+	TraceID     string `db:"trace_id" json:"trace_id,omitempty"`
+	SpanID      string `db:"span_id" json:"span_id,omitempty"`
+	PeerAddress string `db:"peer_address" json:"peer_address,omitempty"`
+	UserAgent   string `db:"user_agent" json:"user_agent,omitempty"`
+	// Method Full gRPC method of the request
+	Method string `db:"grpc_method" json:"grpc_method,omitempty"`
+	// OrgAdmin Whether the action was taken by a user with the org admin role, see [IsOrgAdmin]
+	OrgAdmin bool `db:"org_admin" json:"org_admin,omitempty"`
+	// ServicePrincipal Whether the action was taken by a service principal, User is then the id of its API key,
+	// see [IsServicePrincipal]
+	ServicePrincipal bool `db:"service_principal" json:"service_principal,omitempty"`
+}
+
+// NewAccessEvent Event for an action happening now, with details of the request taken from ctx
+func NewAccessEvent(ctx context.Context, action string, collectionID string, user, org *uuid.UUID) *AccessEvent {
+	out := &AccessEvent{
+		CollectionID:     collectionID,
+		User:             user,
+		OrgID:            org,
+		Action:           action,
+		OccurredAt:       time.Now().UTC(),
+		OrgAdmin:         IsOrgAdmin(ctx),
+		ServicePrincipal: IsServicePrincipal(ctx),
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		out.TraceID = sc.TraceID().String()
+		out.SpanID = sc.SpanID().String()
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		out.PeerAddress = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			out.UserAgent = ua[0]
+		}
+	}
+	out.Method, _ = grpc.Method(ctx)
+
+	return out
+}
+
+// AuditFilter Narrows the events returned from the audit log
+type AuditFilter struct {
+	// Actions Only events with one of these actions, every action when empty
+	Actions []string
+	// Since Only events at or after this time, no lower bound when zero
+	Since time.Time
+	// Until Only events before this time, no upper bound when zero
+	Until time.Time
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"testbert/server/sharetoken"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix Starts every API key so a leaked key can be recognized, by secret scanners for example
+const APIKeyPrefix = "tbk_"
+
+// APIKeyUseInterval How out of date APIKey.LastUsedAt may be, a use is only recorded once the last one is at
+// least this old so busy clients don't write on every request
+const APIKeyUseInterval = time.Minute
+
+// APIKey Credential a machine client authenticates with as a service principal in OrgID, the principal's user
+// id is the key's ID
+type APIKey struct {
+	ID      uuid.UUID `db:"id"`
+	OrgID   uuid.UUID `db:"org_id"`
+	Name    string    `db:"name"`
+	KeyHash string    `db:"key_hash"`
+	// Key The secret key, only known right after it is created
+	Key        string     `db:"-"`
+	CreatedBy  uuid.UUID  `db:"created_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+}
+
+// NewAPIKey returns a new random secret API key
+func NewAPIKey() string {
+	return APIKeyPrefix + sharetoken.NewToken()
+}
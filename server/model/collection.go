@@ -1,14 +1,54 @@
 // Package model TestBert Data Models
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Collection struct {
-	ID       uuid.UUID `db:"id"`
-	UserID   uuid.UUID `db:"user_id"`
-	OrgID    uuid.UUID `db:"org_id"`
-	Data     string    `db:"data"`
-	OrgView  bool      `db:"org_view"`
-	OrgEdit  bool      `db:"org_edit"`
-	OrgShare bool      `db:"org_share"`
+	ID             uuid.UUID  `db:"id"`
+	UserID         uuid.UUID  `db:"user_id"`
+	OrgID          uuid.UUID  `db:"org_id"`
+	Title          string     `db:"title"`
+	Data           string     `db:"data"`
+	DataSize       int64      `db:"data_size"`
+	OrgView        bool       `db:"org_view"`
+	OrgEdit        bool       `db:"org_edit"`
+	OrgShare       bool       `db:"org_share"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+	CreatedBy      uuid.UUID  `db:"created_by"`
+	LastModifiedBy uuid.UUID  `db:"last_modified_by"`
+	Version        int64      `db:"version"`
+	DeletedAt      *time.Time `db:"deleted_at"`
+	DeletedBy      *uuid.UUID `db:"deleted_by"`
 }
+
+type CollectionFilter int
+
+const (
+	// FilterAll Collections owned by the user or visible to the user's org
+	FilterAll CollectionFilter = iota
+	// FilterOwned Collections owned by the user
+	FilterOwned
+	// FilterOrgShared Org visible collections owned by other users in the org
+	FilterOrgShared
+	// FilterGranted Collections owned by other users that the user has been granted access to
+	FilterGranted
+)
+
+// CollectionField Collection fields that can be changed by an update
+type CollectionField int
+
+const (
+	FieldTitle CollectionField = iota
+	FieldData
+	FieldOrgView
+	FieldOrgEdit
+	FieldOrgShare
+)
+
+// AllCollectionFields Fields changed by an update that doesn't specify any
+var AllCollectionFields = []CollectionField{FieldTitle, FieldData, FieldOrgView, FieldOrgEdit, FieldOrgShare}
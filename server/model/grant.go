@@ -0,0 +1,45 @@
+package model
+
+import (
+	"bytes"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Grant Access to a single collection given to a user, who may be in another org, or to every member of a
+// group. Every grant allows viewing the collection
+type Grant struct {
+	CollectionID uuid.UUID `db:"collection_id"`
+	// UserID The user the grant was given to, uuid.Nil for group grants
+	UserID uuid.UUID `db:"user_id"`
+	// GroupID The group the grant was given to, uuid.Nil for user grants
+	GroupID   uuid.UUID `db:"group_id"`
+	CanEdit   bool      `db:"can_edit"`
+	CanShare  bool      `db:"can_share"`
+	GrantedBy uuid.UUID `db:"granted_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// SortGrants Orders grants oldest first, breaking ties by grantee
+func SortGrants(grants []*Grant) {
+	slices.SortFunc(grants, func(a, b *Grant) int {
+		if n := a.CreatedAt.Compare(b.CreatedAt); n != 0 {
+			return n
+		}
+		if n := bytes.Compare(a.UserID[:], b.UserID[:]); n != 0 {
+			return n
+		}
+		return bytes.Compare(a.GroupID[:], b.GroupID[:])
+	})
+}
+
+// SetGrantee Records the user or group g was given to on the event
+func (e *AccessEvent) SetGrantee(g *Grant) {
+	if g.GroupID != uuid.Nil {
+		e.GroupID = &g.GroupID
+	} else {
+		e.GranteeID = &g.UserID
+	}
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Group A named set of users within an org that collections can be granted to
+type Group struct {
+	ID        uuid.UUID `db:"id"`
+	OrgID     uuid.UUID `db:"org_id"`
+	Name      string    `db:"name"`
+	CreatedBy uuid.UUID `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// GroupMember A user's membership of a group, members don't need to be in the group's org
+type GroupMember struct {
+	GroupID   uuid.UUID `db:"group_id"`
+	UserID    uuid.UUID `db:"user_id"`
+	AddedBy   uuid.UUID `db:"added_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
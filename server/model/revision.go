@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Revision struct {
+	CollectionID uuid.UUID `db:"collection_id"`
+	Revision     int64     `db:"revision"`
+	Title        string    `db:"title"`
+	Data         string    `db:"data"`
+	OrgView      bool      `db:"org_view"`
+	OrgEdit      bool      `db:"org_edit"`
+	OrgShare     bool      `db:"org_share"`
+	UserID       uuid.UUID `db:"user_id"`
+	OrgID        uuid.UUID `db:"org_id"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// NewRevision Snapshot of a collection as last modified by a user in the given org
+func NewRevision(c *Collection, org uuid.UUID) *Revision {
+	return &Revision{
+		CollectionID: c.ID,
+		Revision:     c.Version,
+		Title:        c.Title,
+		Data:         c.Data,
+		OrgView:      c.OrgView,
+		OrgEdit:      c.OrgEdit,
+		OrgShare:     c.OrgShare,
+		UserID:       c.LastModifiedBy,
+		OrgID:        org,
+		CreatedAt:    c.UpdatedAt,
+	}
+}
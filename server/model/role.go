@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+
+	"testbert/server/config"
+)
+
+// IsOrgAdmin Whether the request in ctx was made by an admin of the org the user is logged in to
+func IsOrgAdmin(ctx context.Context) bool {
+	role, _ := ctx.Value(config.KeyRole).(string)
+	return role == config.RoleOrgAdmin
+}
+
+// IsServicePrincipal Whether the request in ctx was made by a service principal authenticated with an API key
+func IsServicePrincipal(ctx context.Context) bool {
+	service, _ := ctx.Value(config.KeyServicePrincipal).(bool)
+	return service
+}
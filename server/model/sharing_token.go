@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -8,5 +10,24 @@ type SharingToken struct {
 	UserID       uuid.UUID `db:"user_id"`
 	OrgID        uuid.UUID `db:"org_id"`
 	CollectionID uuid.UUID `db:"collection_id"`
-	Token        string    `db:"token"`
+	// TokenID Non-secret identifier for listing and revoking the token
+	TokenID   string `db:"token_id"`
+	TokenHash string `db:"token_hash"`
+	// Token The secret token, only known right after it is created
+	Token     string     `db:"-"`
+	ExpiresAt *time.Time `db:"expires_at"`
+	// MaxUses Number of times the token can be used, zero for unlimited
+	MaxUses        int64      `db:"max_uses"`
+	UseCount       int64      `db:"use_count"`
+	CreatedAt      time.Time  `db:"created_at"`
+	LastAccessedAt *time.Time `db:"last_accessed_at"`
+}
+
+// Usable Whether the token can still be used to access its collection at the given time
+func (t *SharingToken) Usable(now time.Time) bool {
+	if t.ExpiresAt != nil && !now.Before(*t.ExpiresAt) {
+		return false
+	}
+
+	return t.MaxUses == 0 || t.UseCount < t.MaxUses
 }
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transfer Ownership of a collection being handed to another user, which waits for the new owner to accept
+// it when the collection moves to another org
+type Transfer struct {
+	CollectionID uuid.UUID `db:"collection_id"`
+	FromUserID   uuid.UUID `db:"from_user_id"`
+	ToUserID     uuid.UUID `db:"to_user_id"`
+	ToOrgID      uuid.UUID `db:"to_org_id"`
+	OfferedAt    time.Time `db:"offered_at"`
+}
+
+// MovesOrg Whether the transfer moves c to another org, which needs the new owner's consent
+func (t *Transfer) MovesOrg(c *Collection) bool {
+	return t.ToOrgID != c.OrgID
+}
+
+// RevokesToken Whether sharing token st is revoked when c is transferred. The old owner's tokens are always
+// revoked, and every token is when the collection moves to another org
+func (t *Transfer) RevokesToken(c *Collection, st *SharingToken) bool {
+	return st.UserID == c.UserID || t.MovesOrg(c)
+}
@@ -2,23 +2,215 @@
 package presenters
 
 import (
+	"strconv"
+
 	"testbert/protobuf/collection"
+	"testbert/protobuf/events"
 	"testbert/server/model"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func Collection(in *model.Collection) *collection.Collection {
-	return &collection.Collection{
+	out := &collection.Collection{
 		CollectionId:   in.ID.String(),
 		CollectionData: in.Data,
 		OrgView:        in.OrgView,
 		OrgEdit:        in.OrgEdit,
 		OrgShare:       in.OrgShare,
+		Title:          in.Title,
+		OwnerId:        in.UserID.String(),
+		CreatedBy:      in.CreatedBy.String(),
+		LastModifiedBy: in.LastModifiedBy.String(),
+		CreatedAt:      timestamppb.New(in.CreatedAt),
+		UpdatedAt:      timestamppb.New(in.UpdatedAt),
+		DataSize:       in.DataSize,
+		Etag:           Etag(in.Version),
+	}
+
+	if in.DeletedAt != nil {
+		out.DeletedAt = timestamppb.New(*in.DeletedAt)
 	}
+	if in.DeletedBy != nil {
+		out.DeletedBy = in.DeletedBy.String()
+	}
+
+	return out
+}
+
+func Revision(in *model.Revision) *collection.Revision {
+	return &collection.Revision{
+		CollectionId:   in.CollectionID.String(),
+		Revision:       in.Revision,
+		Title:          in.Title,
+		CollectionData: in.Data,
+		OrgView:        in.OrgView,
+		OrgEdit:        in.OrgEdit,
+		OrgShare:       in.OrgShare,
+		EditedBy:       in.UserID.String(),
+		EditedByOrg:    in.OrgID.String(),
+		CreatedAt:      timestamppb.New(in.CreatedAt),
+	}
+}
+
+func Etag(version int64) string {
+	return strconv.FormatInt(version, 10)
 }
 
 func SharingToken(in *model.SharingToken) *collection.ShareToken {
-	return &collection.ShareToken{
+	out := &collection.ShareToken{
 		CollectionId: in.CollectionID.String(),
 		Token:        in.Token,
+		TokenId:      in.TokenID,
+		MaxUses:      in.MaxUses,
+		UseCount:     in.UseCount,
+		CreatedBy:    in.UserID.String(),
+		CreatedAt:    timestamppb.New(in.CreatedAt),
+	}
+
+	if in.ExpiresAt != nil {
+		out.ExpiresAt = timestamppb.New(*in.ExpiresAt)
+	}
+	if in.LastAccessedAt != nil {
+		out.LastAccessedAt = timestamppb.New(*in.LastAccessedAt)
+	}
+
+	return out
+}
+
+func Grant(in *model.Grant) *collection.Grant {
+	out := &collection.Grant{
+		CollectionId: in.CollectionID.String(),
+		CanEdit:      in.CanEdit,
+		CanShare:     in.CanShare,
+		GrantedBy:    in.GrantedBy.String(),
+		CreatedAt:    timestamppb.New(in.CreatedAt),
+	}
+
+	if in.GroupID != uuid.Nil {
+		out.GroupId = in.GroupID.String()
+	} else {
+		out.UserId = in.UserID.String()
+	}
+
+	return out
+}
+
+func Group(in *model.Group) *collection.Group {
+	return &collection.Group{
+		GroupId:   in.ID.String(),
+		OrgId:     in.OrgID.String(),
+		Name:      in.Name,
+		CreatedBy: in.CreatedBy.String(),
+		CreatedAt: timestamppb.New(in.CreatedAt),
 	}
 }
+
+func GroupMember(in *model.GroupMember) *collection.GroupMember {
+	return &collection.GroupMember{
+		GroupId:   in.GroupID.String(),
+		UserId:    in.UserID.String(),
+		AddedBy:   in.AddedBy.String(),
+		CreatedAt: timestamppb.New(in.CreatedAt),
+	}
+}
+
+func APIKey(in *model.APIKey) *collection.APIKey {
+	out := &collection.APIKey{
+		ApiKeyId:  in.ID.String(),
+		OrgId:     in.OrgID.String(),
+		Name:      in.Name,
+		CreatedBy: in.CreatedBy.String(),
+		CreatedAt: timestamppb.New(in.CreatedAt),
+		Key:       in.Key,
+	}
+
+	if in.LastUsedAt != nil {
+		out.LastUsedAt = timestamppb.New(*in.LastUsedAt)
+	}
+
+	return out
+}
+
+func AuditEvent(in *model.AccessEvent) *collection.AuditEvent {
+	out := &collection.AuditEvent{
+		EventId:          in.ID,
+		Action:           in.Action,
+		CollectionId:     in.CollectionID,
+		TokenFingerprint: in.TokenFingerprint,
+		TokenId:          in.TokenID,
+		OccurredAt:       timestamppb.New(in.OccurredAt),
+		OrgAdmin:         in.OrgAdmin,
+		ServicePrincipal: in.ServicePrincipal,
+	}
+
+	if in.User != nil {
+		out.UserId = in.User.String()
+	}
+	if in.OrgID != nil {
+		out.OrgId = in.OrgID.String()
+	}
+	if in.GranteeID != nil {
+		out.GranteeId = in.GranteeID.String()
+	}
+	if in.GroupID != nil {
+		out.GroupId = in.GroupID.String()
+	}
+
+	return out
+}
+
+var eventActions = map[string]events.Action{
+	model.ActionCreate:            events.Action_ACTION_CREATE,
+	model.ActionRead:              events.Action_ACTION_READ,
+	model.ActionUpdate:            events.Action_ACTION_UPDATE,
+	model.ActionDelete:            events.Action_ACTION_DELETE,
+	model.ActionShare:             events.Action_ACTION_SHARE,
+	model.ActionReadShared:        events.Action_ACTION_READ_SHARED,
+	model.ActionRevoke:            events.Action_ACTION_REVOKE,
+	model.ActionReadRevision:      events.Action_ACTION_READ_REVISION,
+	model.ActionRestore:           events.Action_ACTION_RESTORE,
+	model.ActionUndelete:          events.Action_ACTION_UNDELETE,
+	model.ActionGrant:             events.Action_ACTION_GRANT,
+	model.ActionRevokeGrant:       events.Action_ACTION_REVOKE_GRANT,
+	model.ActionTransfer:          events.Action_ACTION_TRANSFER,
+	model.ActionTransferOffered:   events.Action_ACTION_TRANSFER_OFFERED,
+	model.ActionPurge:             events.Action_ACTION_PURGE,
+	model.ActionDeleteGroup:       events.Action_ACTION_DELETE_GROUP,
+	model.ActionAddGroupMember:    events.Action_ACTION_ADD_GROUP_MEMBER,
+	model.ActionRemoveGroupMember: events.Action_ACTION_REMOVE_GROUP_MEMBER,
+}
+
+func AccessEvent(in *model.AccessEvent) *events.AccessEvent {
+	out := &events.AccessEvent{
+		EventId:          in.ID,
+		Action:           eventActions[in.Action],
+		OccurredAt:       timestamppb.New(in.OccurredAt),
+		CollectionId:     in.CollectionID,
+		TokenFingerprint: in.TokenFingerprint,
+		TokenId:          in.TokenID,
+		TraceId:          in.TraceID,
+		SpanId:           in.SpanID,
+		PeerAddress:      in.PeerAddress,
+		UserAgent:        in.UserAgent,
+		GrpcMethod:       in.Method,
+		OrgAdmin:         in.OrgAdmin,
+		ServicePrincipal: in.ServicePrincipal,
+	}
+
+	if in.User != nil {
+		out.UserId = in.User.String()
+	}
+	if in.OrgID != nil {
+		out.OrgId = in.OrgID.String()
+	}
+	if in.GranteeID != nil {
+		out.GranteeId = in.GranteeID.String()
+	}
+	if in.GroupID != nil {
+		out.GroupId = in.GroupID.String()
+	}
+
+	return out
+}
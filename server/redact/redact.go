@@ -0,0 +1,146 @@
+// Package redact Keeps secrets out of exported traces
+package redact
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Redactor Replaces the values of secret-bearing attributes with their fingerprint
+type Redactor struct {
+	keys        map[attribute.Key]bool
+	fingerprint func(string) string
+}
+
+// NewRedactor creates a Redactor for the attribute keys, fingerprint must be safe to export
+func NewRedactor(keys []string, fingerprint func(string) string) *Redactor {
+	r := &Redactor{
+		keys:        map[attribute.Key]bool{},
+		fingerprint: fingerprint,
+	}
+
+	for _, k := range keys {
+		k = strings.TrimSpace(k)
+		if k != "" {
+			r.keys[attribute.Key(k)] = true
+		}
+	}
+
+	return r
+}
+
+// Span returns a view of s with secrets replaced, including any copy of them found in the status
+// description or in span events such as recorded errors
+func (r *Redactor) Span(s sdktrace.ReadOnlySpan) sdktrace.ReadOnlySpan {
+	secrets := map[string]string{}
+
+	attrs := r.attributes(s.Attributes(), secrets)
+
+	events := make([]sdktrace.Event, len(s.Events()))
+	for i, e := range s.Events() {
+		e.Attributes = r.attributes(e.Attributes, secrets)
+		events[i] = e
+	}
+
+	// secrets are only known once every attribute has been seen
+	for i, e := range events {
+		e.Name = scrub(e.Name, secrets)
+		for j, kv := range e.Attributes {
+			if kv.Value.Type() == attribute.STRING {
+				e.Attributes[j] = kv.Key.String(scrub(kv.Value.AsString(), secrets))
+			}
+		}
+		events[i] = e
+	}
+
+	status := s.Status()
+	status.Description = scrub(status.Description, secrets)
+
+	return &redactedSpan{
+		ReadOnlySpan: s,
+		attributes:   attrs,
+		events:       events,
+		status:       status,
+	}
+}
+
+// attributes returns a copy of in with redacted values, collecting the originals into secrets
+func (r *Redactor) attributes(in []attribute.KeyValue, secrets map[string]string) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, len(in))
+	for i, kv := range in {
+		if !r.keys[kv.Key] {
+			out[i] = kv
+			continue
+		}
+
+		value := kv.Value.Emit()
+		if value == "" {
+			out[i] = kv
+			continue
+		}
+
+		if _, ok := secrets[value]; !ok {
+			secrets[value] = r.fingerprint(value)
+		}
+		out[i] = kv.Key.String(secrets[value])
+	}
+
+	return out
+}
+
+func scrub(s string, secrets map[string]string) string {
+	for secret, fingerprint := range secrets {
+		s = strings.ReplaceAll(s, secret, fingerprint)
+	}
+	return s
+}
+
+type redactedSpan struct {
+	sdktrace.ReadOnlySpan
+	attributes []attribute.KeyValue
+	events     []sdktrace.Event
+	status     sdktrace.Status
+}
+
+func (s *redactedSpan) Attributes() []attribute.KeyValue {
+	return s.attributes
+}
+
+func (s *redactedSpan) Events() []sdktrace.Event {
+	return s.events
+}
+
+func (s *redactedSpan) Status() sdktrace.Status {
+	return s.status
+}
+
+type exporter struct {
+	next     sdktrace.SpanExporter
+	redactor *Redactor
+}
+
+// NewExporter wraps next so every span is redacted before it is exported
+func NewExporter(next sdktrace.SpanExporter, r *Redactor) sdktrace.SpanExporter {
+	return &exporter{
+		next:     next,
+		redactor: r,
+	}
+}
+
+// ExportSpans implements [sdktrace.SpanExporter].
+func (e *exporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	out := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		out[i] = e.redactor.Span(s)
+	}
+
+	return e.next.ExportSpans(ctx, out)
+}
+
+// Shutdown implements [sdktrace.SpanExporter].
+func (e *exporter) Shutdown(ctx context.Context) error {
+	return e.next.Shutdown(ctx)
+}
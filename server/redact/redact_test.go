@@ -0,0 +1,113 @@
+package redact
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"testbert/server/redact/redacttest"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func fingerprint(s string) string {
+	return "fp-" + strings.ToUpper(s[:2])
+}
+
+func TestExporter(t *testing.T) {
+	tests := []struct {
+		name        string
+		keys        []string
+		record      func(tracer trace.Tracer)
+		secret      string
+		wantAttr    attribute.KeyValue
+		wantStatus  string
+		wantMessage string
+	}{
+		{
+			name: "redacts configured attribute",
+			keys: []string{"share.token"},
+			record: func(tracer trace.Tracer) {
+				_, span := tracer.Start(context.Background(), "op")
+				span.SetAttributes(attribute.String("share.token", "secret-token"))
+				span.End()
+			},
+			secret:   "secret-token",
+			wantAttr: attribute.String("share.token", "fp-SE"),
+		},
+		{
+			name: "keeps other attributes",
+			keys: []string{"share.token"},
+			record: func(tracer trace.Tracer) {
+				_, span := tracer.Start(context.Background(), "op")
+				span.SetAttributes(attribute.String("collection.id", "visible"))
+				span.End()
+			},
+			wantAttr: attribute.String("collection.id", "visible"),
+		},
+		{
+			name: "scrubs secret from status and recorded errors",
+			keys: []string{" share.token ", ""},
+			record: func(tracer trace.Tracer) {
+				_, span := tracer.Start(context.Background(), "op")
+				span.SetAttributes(attribute.String("share.token", "secret-token"))
+				span.RecordError(errors.New("no token secret-token"))
+				span.SetStatus(codes.Error, "lookup secret-token failed")
+				span.End()
+			},
+			secret:      "secret-token",
+			wantAttr:    attribute.String("share.token", "fp-SE"),
+			wantStatus:  "lookup fp-SE failed",
+			wantMessage: "no token fp-SE",
+		},
+		{
+			name: "redacts attributes on events",
+			keys: []string{"collection.data"},
+			record: func(tracer trace.Tracer) {
+				_, span := tracer.Start(context.Background(), "op")
+				span.AddEvent("read", trace.WithAttributes(attribute.String("collection.data", "private data")))
+				span.SetStatus(codes.Error, "bad private data")
+				span.End()
+			},
+			secret:     "private data",
+			wantStatus: "bad fp-PR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(
+				sdktrace.WithSyncer(NewExporter(memory, NewRedactor(tt.keys, fingerprint))),
+			)
+
+			tt.record(provider.Tracer("test"))
+
+			spans := memory.GetSpans()
+			if !assert.Len(t, spans, 1) {
+				return
+			}
+			span := spans[0]
+
+			if tt.wantAttr.Valid() {
+				assert.Contains(t, span.Attributes, tt.wantAttr)
+			}
+			if tt.wantStatus != "" {
+				assert.Equal(t, tt.wantStatus, span.Status.Description)
+			}
+			if tt.wantMessage != "" && assert.Len(t, span.Events, 1) {
+				assert.Contains(t, span.Events[0].Attributes, attribute.String("exception.message", tt.wantMessage))
+			}
+
+			if tt.secret != "" {
+				assert.NotContains(t, redacttest.Dump(span), tt.secret)
+			}
+		})
+	}
+}
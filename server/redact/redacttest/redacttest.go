@@ -0,0 +1,25 @@
+// Package redacttest Helpers for testing that secrets are kept out of exported traces
+package redacttest
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Dump renders every exported string of a span, so tests can check a secret appears nowhere in it
+func Dump(s tracetest.SpanStub) string {
+	var b strings.Builder
+	b.WriteString(s.Name)
+	b.WriteString(s.Status.Description)
+	for _, kv := range s.Attributes {
+		b.WriteString(kv.Value.Emit())
+	}
+	for _, e := range s.Events {
+		b.WriteString(e.Name)
+		for _, kv := range e.Attributes {
+			b.WriteString(kv.Value.Emit())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"testbert/protobuf/collection"
+	"testbert/server/presenters"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const maxAPIKeyNameLength = 100
+
+// CreateAPIKey implements [collection.CollectionServiceServer].
+func (s *collectionServer) CreateAPIKey(ctx context.Context, req *collection.CreateAPIKeyRequest) (*collection.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "CreateAPIKey")
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	if err := s.authorizeOrgAPIKeys(ctx, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "create api key failed")
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxAPIKeyNameLength {
+		span.RecordError(tberrors.ErrInvalidAPIKeyName)
+		span.SetStatus(codes.Error, "invalid api key name")
+		return nil, tberrors.ErrInvalidAPIKeyName
+	}
+
+	out, err := s.store.CreateAPIKey(ctx, name, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "create api key failed")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("api_key.id", out.ID.String()))
+
+	return presenters.APIKey(out), nil
+}
+
+// ListAPIKeys implements [collection.CollectionServiceServer].
+func (s *collectionServer) ListAPIKeys(ctx context.Context, req *collection.ListAPIKeysRequest) (*collection.ListAPIKeysResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListAPIKeys")
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	if err := s.authorizeOrgAPIKeys(ctx, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list api keys failed")
+		return nil, err
+	}
+
+	found, err := s.store.ListAPIKeys(ctx, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list api keys failed")
+		return nil, err
+	}
+
+	out := &collection.ListAPIKeysResponse{}
+	for _, k := range found {
+		out.ApiKeys = append(out.ApiKeys, presenters.APIKey(k))
+	}
+
+	span.SetAttributes(attribute.Int("api_key.count", len(out.ApiKeys)))
+
+	return out, nil
+}
+
+// RevokeAPIKey implements [collection.CollectionServiceServer].
+func (s *collectionServer) RevokeAPIKey(ctx context.Context, req *collection.RevokeAPIKeyRequest) (*emptypb.Empty, error) {
+	ctx, span := tracer.Start(ctx, "RevokeAPIKey",
+		trace.WithAttributes(
+			attribute.String("api_key.id", req.ApiKeyId),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.ApiKeyId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid api key id")
+		return nil, tberrors.ErrAPIKeyNotFound
+	}
+
+	if err := s.authorizeAPIKey(ctx, &id, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "revoke api key failed")
+		return nil, err
+	}
+
+	err = s.store.DeleteAPIKey(ctx, &id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "revoke api key failed")
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
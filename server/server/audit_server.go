@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"testbert/protobuf/collection"
+	"testbert/server/authz"
+	"testbert/server/model"
+	"testbert/server/presenters"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetAuditLog implements [collection.CollectionServiceServer].
+func (s *collectionServer) GetAuditLog(ctx context.Context, req *collection.GetAuditLogRequest) (*collection.GetAuditLogResponse, error) {
+	ctx, span := tracer.Start(ctx, "GetAuditLog",
+		trace.WithAttributes(
+			attribute.String("collection.id", req.CollectionId),
+			attribute.Int("page.size", int(req.PageSize)),
+			attribute.StringSlice("audit.actions", req.Actions),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.CollectionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid collection id")
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	pageSize, err := normalizePageSize(req.PageSize)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid page size")
+		return nil, err
+	}
+
+	before, err := decodeSequencePageToken(req.PageToken)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid page token")
+		return nil, tberrors.ErrInvalidPageToken
+	}
+
+	filter, err := auditFilter(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid audit filter")
+		return nil, err
+	}
+
+	// Collections in the trash can still be audited
+	c, err := s.store.GetCollectionIncludingTrash(ctx, &id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list audit events failed")
+		return nil, err
+	}
+
+	if err := s.authorizeCollection(ctx, authz.ActionManage, c, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list audit events failed")
+		return nil, err
+	}
+
+	// Fetch one extra to find out if there is another page
+	found, err := s.store.ListAuditEvents(ctx, &id, filter, before, pageSize+1, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list audit events failed")
+		return nil, err
+	}
+
+	out := &collection.GetAuditLogResponse{}
+	if len(found) > pageSize {
+		found = found[:pageSize]
+		out.NextPageToken = encodeSequencePageToken(found[pageSize-1].ID)
+	}
+
+	for _, e := range found {
+		out.Events = append(out.Events, presenters.AuditEvent(e))
+	}
+
+	span.SetAttributes(attribute.Int("audit.event.count", len(out.Events)))
+
+	return out, nil
+}
+
+// auditFilter validates the filter fields of a GetAuditLog request
+func auditFilter(req *collection.GetAuditLogRequest) (*model.AuditFilter, error) {
+	out := &model.AuditFilter{}
+
+	for _, action := range req.Actions {
+		if !model.IsAction(action) {
+			return nil, tberrors.ErrInvalidAuditFilter
+		}
+		out.Actions = append(out.Actions, action)
+	}
+
+	var err error
+	if out.Since, err = optionalTime(req.Since); err != nil {
+		return nil, err
+	}
+	if out.Until, err = optionalTime(req.Until); err != nil {
+		return nil, err
+	}
+
+	if !out.Since.IsZero() && !out.Until.IsZero() && !out.Since.Before(out.Until) {
+		return nil, tberrors.ErrInvalidAuditFilter
+	}
+
+	return out, nil
+}
+
+// optionalTime returns the time for ts, or the zero time when it is unset
+func optionalTime(ts *timestamppb.Timestamp) (time.Time, error) {
+	if ts == nil {
+		return time.Time{}, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return time.Time{}, tberrors.ErrInvalidAuditFilter
+	}
+
+	return ts.AsTime(), nil
+}
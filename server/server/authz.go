@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+
+	"testbert/server/authz"
+	"testbert/server/datastore"
+	"testbert/server/model"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// authorize decides whether the logged in user may take the action on a live collection, returning the
+// collection the decision was made for
+func (s *collectionServer) authorize(ctx context.Context, a authz.Action, id, user, org *uuid.UUID) (*model.Collection, error) {
+	c, err := s.store.GetCollection(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeCollection(ctx, a, c, user, org); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// authorizeCollection decides whether the logged in user may take the action on c, which may be in the trash
+func (s *collectionServer) authorizeCollection(ctx context.Context, a authz.Action, c *model.Collection, user, org *uuid.UUID) error {
+	g, err := s.store.GetEffectiveGrant(ctx, &c.ID, user)
+	if err != nil {
+		return err
+	}
+
+	return s.decide(ctx, a, &authz.Resource{Collection: c, Grant: g}, user, org).Err(tberrors.ErrCollectionNotFound)
+}
+
+// check decides whether the logged in user may take the action on a collection the store is changing, see
+// [datastore.CollectionCheck]
+func (s *collectionServer) check(ctx context.Context, a authz.Action, user, org *uuid.UUID) datastore.CollectionCheck {
+	return func(c *model.Collection, g *model.Grant) error {
+		return s.decide(ctx, a, &authz.Resource{Collection: c, Grant: g}, user, org).Err(tberrors.ErrCollectionNotFound)
+	}
+}
+
+// checkToken decides whether the logged in user may revoke t, given the collection it is for
+func (s *collectionServer) checkToken(ctx context.Context, t *model.SharingToken, user, org *uuid.UUID) datastore.CollectionCheck {
+	return func(c *model.Collection, g *model.Grant) error {
+		r := &authz.Resource{Collection: c, Grant: g, Token: t}
+		return s.decide(ctx, authz.ActionRevokeToken, r, user, org).Err(tberrors.ErrTokenNotFound)
+	}
+}
+
+// authorizeGroup decides whether the logged in user may take the action on a group
+func (s *collectionServer) authorizeGroup(ctx context.Context, a authz.Action, id, user, org *uuid.UUID) error {
+	g, err := s.store.GetGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.decide(ctx, a, &authz.Resource{Group: g}, user, org).Err(tberrors.ErrGroupNotFound)
+}
+
+// authorizeAPIKey decides whether the logged in user may manage an API key
+func (s *collectionServer) authorizeAPIKey(ctx context.Context, id, user, org *uuid.UUID) error {
+	k, err := s.store.GetAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.decide(ctx, authz.ActionManageAPIKey, &authz.Resource{APIKey: k}, user, org).Err(tberrors.ErrAPIKeyNotFound)
+}
+
+// authorizeOrgAPIKeys decides whether the logged in user may create and list the API keys of their org
+func (s *collectionServer) authorizeOrgAPIKeys(ctx context.Context, user, org *uuid.UUID) error {
+	r := &authz.Resource{APIKey: &model.APIKey{OrgID: *org}}
+	return s.decide(ctx, authz.ActionManageAPIKey, r, user, org).Err(tberrors.ErrAPIKeyNotFound)
+}
+
+// allowed keeps the collections a store listed that the logged in user may take the action on, calling list
+// for more until limit are kept or the store has no more
+func (s *collectionServer) allowed(ctx context.Context, a authz.Action, list func(after *uuid.UUID, limit int) ([]*model.Collection, error), after *uuid.UUID, limit int, user, org *uuid.UUID) ([]*model.Collection, error) {
+	subject := authz.NewSubject(ctx, user, org)
+
+	out := []*model.Collection{}
+	for len(out) < limit {
+		found, err := list(after, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]uuid.UUID, 0, len(found))
+		for _, c := range found {
+			ids = append(ids, c.ID)
+		}
+
+		grants, err := s.store.GetEffectiveGrants(ctx, ids, user)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range found {
+			r := &authz.Resource{Collection: c, Grant: grants[c.ID]}
+			if len(out) < limit && s.policy.Decide(subject, a, r) == authz.Allow {
+				out = append(out, c)
+			}
+		}
+
+		if len(found) < limit {
+			break
+		}
+		after = &found[len(found)-1].ID
+	}
+
+	return out, nil
+}
+
+// decide evaluates the policy, recording the decision on the current span
+func (s *collectionServer) decide(ctx context.Context, a authz.Action, r *authz.Resource, user, org *uuid.UUID) authz.Decision {
+	d := s.policy.Decide(authz.NewSubject(ctx, user, org), a, r)
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("authz.action", string(a)),
+		attribute.Bool("authz.allowed", d == authz.Allow),
+	)
+
+	return d
+}
@@ -3,14 +3,20 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
+	"slices"
+	"strconv"
 	"time"
 
 	"testbert/protobuf/collection"
+	"testbert/server/authz"
 	"testbert/server/config"
 	"testbert/server/datastore"
+	"testbert/server/events"
 	"testbert/server/interceptors/metrics"
 	"testbert/server/model"
 	"testbert/server/presenters"
+	"testbert/server/sharetoken"
 	"testbert/server/tberrors"
 
 	"github.com/google/uuid"
@@ -20,15 +26,23 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 var tracer = otel.Tracer("collection-server")
 
+const (
+	defaultPageSize = 25
+	maxPageSize     = 100
+)
+
 type collectionServer struct {
 	collection.UnimplementedCollectionServiceServer
-	store   datastore.TestBertDatastore
-	cache   *otter.Cache[string, int]
-	publish chan any
+	store  datastore.TestBertDatastore
+	policy authz.Policy
+	cache  *otter.Cache[string, int]
+	events *events.Dispatcher
+	hasher *sharetoken.Hasher
 }
 
 // CreateCollection implements [collection.CollectionServiceServer].
@@ -51,8 +65,9 @@ func (s *collectionServer) CreateCollection(ctx context.Context, req *collection
 		attribute.String("org.id", org.String()),
 	)
 
-	out, err := s.store.CreateCollection(&model.Collection{
+	out, err := s.store.CreateCollection(ctx, &model.Collection{
 		ID:       uuid.New(),
+		Title:    req.Title,
 		Data:     req.CollectionData,
 		OrgView:  req.OrgView,
 		OrgEdit:  req.OrgEdit,
@@ -66,12 +81,6 @@ func (s *collectionServer) CreateCollection(ctx context.Context, req *collection
 
 	span.SetAttributes(attribute.String("collection.id", out.ID.String()))
 
-	s.publish <- &AccessEvent{
-		CollectionID: string(out.ID.String()),
-		User:         user,
-		OrgID:        org,
-		Action:       "create",
-	}
 	return presenters.Collection(out), nil
 }
 
@@ -102,22 +111,40 @@ func (s *collectionServer) CreateShareToken(ctx context.Context, req *collection
 		return nil, tberrors.ErrCollectionNotFound
 	}
 
-	out, err := s.store.CreateSharingToken(&id, user, org)
+	if req.MaxUses < 0 {
+		span.RecordError(tberrors.ErrInvalidMaxUses)
+		span.SetStatus(codes.Error, "invalid max uses")
+		return nil, tberrors.ErrInvalidMaxUses
+	}
+
+	in := &model.SharingToken{
+		CollectionID: id,
+		MaxUses:      req.MaxUses,
+	}
+
+	if req.ExpiresAt != nil {
+		if err := req.ExpiresAt.CheckValid(); err != nil || !req.ExpiresAt.AsTime().After(time.Now()) {
+			span.RecordError(tberrors.ErrInvalidExpiry)
+			span.SetStatus(codes.Error, "invalid expiry")
+			return nil, tberrors.ErrInvalidExpiry
+		}
+		expiresAt := req.ExpiresAt.AsTime()
+		in.ExpiresAt = &expiresAt
+	}
+
+	out, err := s.store.CreateSharingToken(ctx, in, user, org, s.check(ctx, authz.ActionShare, user, org))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "create share token failed")
 		return nil, err
 	}
 
-	span.SetAttributes(attribute.String("share.token", out.Token))
+	fingerprint := s.hasher.Fingerprint(out.Token)
+	span.SetAttributes(
+		attribute.String("share.token.fingerprint", fingerprint),
+		attribute.String("share.token_id", out.TokenID),
+	)
 
-	s.publish <- &AccessEvent{
-		SharedToken:  out.Token,
-		CollectionID: string(id.String()),
-		User:         user,
-		OrgID:        org,
-		Action:       "share",
-	}
 	return presenters.SharingToken(out), nil
 }
 
@@ -148,19 +175,20 @@ func (s *collectionServer) DeleteCollection(ctx context.Context, req *collection
 		return nil, tberrors.ErrCollectionNotFound
 	}
 
-	err = s.store.DeleteCollection(&id, user, org)
+	version, err := parseEtag(req.Etag)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid etag")
+		return nil, tberrors.ErrEtagMismatch
+	}
+
+	err = s.store.DeleteCollection(ctx, &id, version, user, org, s.check(ctx, authz.ActionEdit, user, org))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "delete collection failed")
 		return nil, err
 	}
 
-	s.publish <- &AccessEvent{
-		CollectionID: string(id.String()),
-		User:         user,
-		OrgID:        org,
-		Action:       "delete",
-	}
 	return &emptypb.Empty{}, nil
 }
 
@@ -191,27 +219,23 @@ func (s *collectionServer) GetCollection(ctx context.Context, req *collection.Ge
 		return nil, tberrors.ErrCollectionNotFound
 	}
 
-	out, err := s.store.GetCollection(&id, user, org)
+	out, err := s.authorize(ctx, authz.ActionView, &id, user, org)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "get collection failed")
 		return nil, err
 	}
 
-	s.publish <- &AccessEvent{
-		CollectionID: string(id.String()),
-		User:         user,
-		OrgID:        org,
-		Action:       "read",
-	}
+	s.publishEvent(ctx, model.NewAccessEvent(ctx, model.ActionRead, id.String(), user, org))
 	return presenters.Collection(out), nil
 }
 
 // GetSharedCollection implements [collection.CollectionServiceServer].
 func (s *collectionServer) GetSharedCollection(ctx context.Context, req *collection.GetSharedCollectionRequest) (*collection.Collection, error) {
+	fingerprint := s.hasher.Fingerprint(req.Token)
 	ctx, span := tracer.Start(ctx, "GetSharedCollection",
 		trace.WithAttributes(
-			attribute.String("share.token", req.Token),
+			attribute.String("share.token.fingerprint", fingerprint),
 		))
 	defer span.End()
 
@@ -230,7 +254,7 @@ func (s *collectionServer) GetSharedCollection(ctx context.Context, req *collect
 		return nil, tberrors.ErrRateLimited
 	}
 
-	out, err := s.store.GetCollectionFromSharingToken(req.Token)
+	out, err := s.store.GetCollectionFromSharingToken(ctx, req.Token)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "get shared collection failed")
@@ -239,21 +263,20 @@ func (s *collectionServer) GetSharedCollection(ctx context.Context, req *collect
 
 	span.SetAttributes(attribute.String("collection.id", out.ID.String()))
 
-	s.publish <- &AccessEvent{
-		SharedToken:  req.Token,
-		CollectionID: out.ID.String(),
-		User:         nil,
-		OrgID:        nil,
-		Action:       "readShared",
-	}
 	return presenters.Collection(out), nil
 }
 
 // RevokeShareToken implements [collection.CollectionServiceServer].
 func (s *collectionServer) RevokeShareToken(ctx context.Context, req *collection.RevokeShareTokenRequest) (*emptypb.Empty, error) {
+	var fingerprint string
+	if req.Token != "" {
+		fingerprint = s.hasher.Fingerprint(req.Token)
+	}
+
 	ctx, span := tracer.Start(ctx, "RevokeShareToken",
 		trace.WithAttributes(
-			attribute.String("share.token", req.Token),
+			attribute.String("share.token.fingerprint", fingerprint),
+			attribute.String("share.token_id", req.TokenId),
 		))
 	defer span.End()
 
@@ -269,23 +292,152 @@ func (s *collectionServer) RevokeShareToken(ctx context.Context, req *collection
 		attribute.String("org.id", org.String()),
 	)
 
-	err = s.store.DeleteSharingToken(req.Token, user, org)
+	var t *model.SharingToken
+	if req.TokenId != "" {
+		t, err = s.store.GetSharingTokenByID(ctx, req.TokenId)
+	} else {
+		t, err = s.store.GetSharingToken(ctx, req.Token)
+	}
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "revoke share token failed")
 		return nil, err
 	}
 
-	s.publish <- &AccessEvent{
-		SharedToken:  req.Token,
-		CollectionID: "",
-		User:         user,
-		OrgID:        org,
-		Action:       "revoke",
+	err = s.store.DeleteSharingTokenByID(ctx, t.TokenID, user, org, s.checkToken(ctx, t, user, org))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "revoke share token failed")
+		return nil, err
 	}
+
 	return &emptypb.Empty{}, nil
 }
 
+// ListShareTokens implements [collection.CollectionServiceServer].
+func (s *collectionServer) ListShareTokens(ctx context.Context, req *collection.ListShareTokensRequest) (*collection.ListShareTokensResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListShareTokens",
+		trace.WithAttributes(
+			attribute.String("collection.id", req.CollectionId),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.CollectionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid collection id")
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	if _, err := s.authorize(ctx, authz.ActionShare, &id, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list share tokens failed")
+		return nil, err
+	}
+
+	found, err := s.store.ListSharingTokens(ctx, &id, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list share tokens failed")
+		return nil, err
+	}
+
+	out := &collection.ListShareTokensResponse{}
+	for _, t := range found {
+		out.Tokens = append(out.Tokens, presenters.SharingToken(t))
+	}
+
+	span.SetAttributes(attribute.Int("share.token.count", len(out.Tokens)))
+
+	return out, nil
+}
+
+// ListCollections implements [collection.CollectionServiceServer].
+func (s *collectionServer) ListCollections(ctx context.Context, req *collection.ListCollectionsRequest) (*collection.ListCollectionsResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListCollections",
+		trace.WithAttributes(
+			attribute.String("collection.filter", req.Filter.String()),
+			attribute.Int("page.size", int(req.PageSize)),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	pageSize, err := normalizePageSize(req.PageSize)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid page size")
+		return nil, err
+	}
+
+	after, err := decodePageToken(req.PageToken)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid page token")
+		return nil, tberrors.ErrInvalidPageToken
+	}
+
+	var filter model.CollectionFilter
+	switch req.Filter {
+	case collection.CollectionFilter_COLLECTION_FILTER_OWNED:
+		filter = model.FilterOwned
+	case collection.CollectionFilter_COLLECTION_FILTER_ORG_SHARED:
+		filter = model.FilterOrgShared
+	case collection.CollectionFilter_COLLECTION_FILTER_GRANTED:
+		filter = model.FilterGranted
+	default:
+		filter = model.FilterAll
+	}
+
+	list := func(after *uuid.UUID, limit int) ([]*model.Collection, error) {
+		return s.store.ListCollections(ctx, filter, after, limit, user, org)
+	}
+
+	// Fetch one extra to find out if there is another page
+	found, err := s.allowed(ctx, authz.ActionView, list, after, pageSize+1, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list collections failed")
+		return nil, err
+	}
+
+	out := &collection.ListCollectionsResponse{}
+	if len(found) > pageSize {
+		found = found[:pageSize]
+		out.NextPageToken = encodePageToken(&found[pageSize-1].ID)
+	}
+
+	for _, c := range found {
+		out.Collections = append(out.Collections, presenters.Collection(c))
+	}
+
+	span.SetAttributes(attribute.Int("collection.count", len(out.Collections)))
+
+	return out, nil
+}
+
 func (s *collectionServer) UpdateCollection(ctx context.Context, req *collection.UpdateCollectionRequest) (*collection.Collection, error) {
 	ctx, span := tracer.Start(ctx, "UpdateCollection",
 		trace.WithAttributes(
@@ -312,49 +464,61 @@ func (s *collectionServer) UpdateCollection(ctx context.Context, req *collection
 		return nil, tberrors.ErrCollectionNotFound
 	}
 
-	out, err := s.store.UpdateCollection(&model.Collection{
+	version, err := parseEtag(req.Etag)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid etag")
+		return nil, tberrors.ErrEtagMismatch
+	}
+
+	fields, err := updateMaskFields(req.UpdateMask)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid update mask")
+		return nil, err
+	}
+
+	out, err := s.store.UpdateCollection(ctx, &model.Collection{
 		ID:       id,
+		Title:    req.Title,
 		Data:     req.CollectionData,
 		OrgView:  req.OrgView,
 		OrgEdit:  req.OrgEdit,
 		OrgShare: req.OrgShare,
-		UserID:   *user,
-		OrgID:    *org,
-	}, user, org)
+		Version:  version,
+	}, fields, user, org, s.check(ctx, authz.ActionEdit, user, org))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "update collection failed")
 		return nil, err
 	}
 
-	s.publish <- &AccessEvent{
-		CollectionID: string(id.String()),
-		User:         user,
-		OrgID:        org,
-		Action:       "update",
-	}
 	return presenters.Collection(out), nil
 }
 
-func NewCollectionServer(store datastore.TestBertDatastore, key string) collection.CollectionServiceServer {
-	publish := make(chan any, 1000)
-
-	go func() {
-		for msg := range publish {
-			// publish the message to a queue for counts, dashboards, and analysis
-			_ = msg
-		}
-	}()
-
+func NewCollectionServer(store datastore.TestBertDatastore, policy authz.Policy, key string, hasher *sharetoken.Hasher, dispatcher *events.Dispatcher) collection.CollectionServiceServer {
 	return &collectionServer{
-		store: store,
+		store:  store,
+		policy: policy,
 		cache: otter.Must(&otter.Options[string, int]{
 			ExpiryCalculator: otter.ExpiryCreating[string, int](15 * time.Second),
 		}),
-		publish: publish,
+		events: dispatcher,
+		hasher: hasher,
 	}
 }
 
+// publishEvent records e in the audit log and queues it for the event publisher, events for changes are
+// recorded by the store instead
+func (s *collectionServer) publishEvent(ctx context.Context, e *model.AccessEvent) {
+	// the access already happened, so failing to audit it is recorded rather than failing the request
+	if err := s.store.RecordAccess(ctx, e); err != nil {
+		trace.SpanFromContext(ctx).RecordError(err)
+	}
+
+	s.events.Dispatch(ctx, e)
+}
+
 func getLoggedInUserAndOrg(ctx context.Context) (*uuid.UUID, *uuid.UUID, error) {
 	user, ok := ctx.Value(config.KeyUserID).(*uuid.UUID)
 	if !ok {
@@ -367,10 +531,84 @@ func getLoggedInUserAndOrg(ctx context.Context) (*uuid.UUID, *uuid.UUID, error)
 	return user, org, nil
 }
 
-type AccessEvent struct {
-	SharedToken  string
-	CollectionID string
-	User         *uuid.UUID
-	OrgID        *uuid.UUID
-	Action       string
+func normalizePageSize(size int32) (int, error) {
+	switch {
+	case size < 0:
+		return 0, tberrors.ErrInvalidPageSize
+	case size == 0:
+		return defaultPageSize, nil
+	case size > maxPageSize:
+		return maxPageSize, nil
+	}
+
+	return int(size), nil
+}
+
+func encodePageToken(after *uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(after[:])
+}
+
+func decodePageToken(token string) (*uuid.UUID, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := uuid.FromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
+
+// updatableFields maps update mask paths to the collection fields they change
+var updatableFields = map[string]model.CollectionField{
+	"title":           model.FieldTitle,
+	"collection_data": model.FieldData,
+	"org_view":        model.FieldOrgView,
+	"org_edit":        model.FieldOrgEdit,
+	"org_share":       model.FieldOrgShare,
+}
+
+// updateMaskFields returns the collection fields named by an update mask, or nil when every field should
+// be updated
+func updateMaskFields(mask *fieldmaskpb.FieldMask) ([]model.CollectionField, error) {
+	if len(mask.GetPaths()) == 0 {
+		return nil, nil
+	}
+
+	out := []model.CollectionField{}
+	for _, path := range mask.GetPaths() {
+		f, ok := updatableFields[path]
+		if !ok {
+			return nil, tberrors.ErrInvalidUpdateMask
+		}
+		if !slices.Contains(out, f) {
+			out = append(out, f)
+		}
+	}
+
+	return out, nil
+}
+
+// parseEtag returns the collection version for an etag, or zero when no etag was given
+func parseEtag(etag string) (int64, error) {
+	if etag == "" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseInt(etag, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if version < 1 {
+		return 0, strconv.ErrRange
+	}
+
+	return version, nil
 }
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+
+	"testbert/protobuf/collection"
+	"testbert/server/authz"
+	"testbert/server/model"
+	"testbert/server/presenters"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// GrantAccess implements [collection.CollectionServiceServer].
+func (s *collectionServer) GrantAccess(ctx context.Context, req *collection.GrantAccessRequest) (*collection.Grant, error) {
+	ctx, span := tracer.Start(ctx, "GrantAccess",
+		trace.WithAttributes(
+			attribute.String("collection.id", req.CollectionId),
+			attribute.String("grantee.id", req.UserId),
+			attribute.String("grantee.group_id", req.GroupId),
+			attribute.Bool("grant.can_edit", req.CanEdit),
+			attribute.Bool("grant.can_share", req.CanShare),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.CollectionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid collection id")
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	g, err := grantee(id, req.UserId, req.GroupId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid grantee")
+		return nil, err
+	}
+	g.CanEdit = req.CanEdit
+	g.CanShare = req.CanShare
+
+	out, err := s.store.GrantAccess(ctx, g, user, org, s.check(ctx, authz.ActionManage, user, org))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "grant access failed")
+		return nil, err
+	}
+
+	return presenters.Grant(out), nil
+}
+
+// RevokeAccess implements [collection.CollectionServiceServer].
+func (s *collectionServer) RevokeAccess(ctx context.Context, req *collection.RevokeAccessRequest) (*emptypb.Empty, error) {
+	ctx, span := tracer.Start(ctx, "RevokeAccess",
+		trace.WithAttributes(
+			attribute.String("collection.id", req.CollectionId),
+			attribute.String("grantee.id", req.UserId),
+			attribute.String("grantee.group_id", req.GroupId),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.CollectionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid collection id")
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	g, err := grantee(id, req.UserId, req.GroupId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid grantee")
+		return nil, tberrors.ErrGrantNotFound
+	}
+
+	err = s.store.RevokeAccess(ctx, g, user, org, s.check(ctx, authz.ActionManage, user, org))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "revoke access failed")
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListGrants implements [collection.CollectionServiceServer].
+func (s *collectionServer) ListGrants(ctx context.Context, req *collection.ListGrantsRequest) (*collection.ListGrantsResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListGrants",
+		trace.WithAttributes(
+			attribute.String("collection.id", req.CollectionId),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.CollectionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid collection id")
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	// Only those who can change the grants see them
+	if _, err := s.authorize(ctx, authz.ActionManage, &id, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list grants failed")
+		return nil, err
+	}
+
+	found, err := s.store.ListGrants(ctx, &id, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list grants failed")
+		return nil, err
+	}
+
+	out := &collection.ListGrantsResponse{}
+	for _, g := range found {
+		out.Grants = append(out.Grants, presenters.Grant(g))
+	}
+
+	span.SetAttributes(attribute.Int("grant.count", len(out.Grants)))
+
+	return out, nil
+}
+
+// grantee identifies the user, or the group when groupID is set, a grant on the collection is given to
+func grantee(collectionID uuid.UUID, userID, groupID string) (*model.Grant, error) {
+	out := &model.Grant{CollectionID: collectionID}
+
+	var err error
+	if groupID != "" {
+		if out.GroupID, err = uuid.Parse(groupID); err != nil {
+			return nil, tberrors.ErrGroupNotFound
+		}
+	} else if out.UserID, err = uuid.Parse(userID); err != nil {
+		return nil, tberrors.ErrInvalidGrantee
+	}
+
+	return out, nil
+}
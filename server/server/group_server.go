@@ -0,0 +1,285 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"testbert/protobuf/collection"
+	"testbert/server/authz"
+	"testbert/server/presenters"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const maxGroupNameLength = 100
+
+// CreateGroup implements [collection.CollectionServiceServer].
+func (s *collectionServer) CreateGroup(ctx context.Context, req *collection.CreateGroupRequest) (*collection.Group, error) {
+	ctx, span := tracer.Start(ctx, "CreateGroup")
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxGroupNameLength {
+		span.RecordError(tberrors.ErrInvalidGroupName)
+		span.SetStatus(codes.Error, "invalid group name")
+		return nil, tberrors.ErrInvalidGroupName
+	}
+
+	out, err := s.store.CreateGroup(ctx, name, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "create group failed")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("group.id", out.ID.String()))
+
+	return presenters.Group(out), nil
+}
+
+// ListGroups implements [collection.CollectionServiceServer].
+func (s *collectionServer) ListGroups(ctx context.Context, req *collection.ListGroupsRequest) (*collection.ListGroupsResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListGroups")
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	found, err := s.store.ListGroups(ctx, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list groups failed")
+		return nil, err
+	}
+
+	out := &collection.ListGroupsResponse{}
+	for _, g := range found {
+		out.Groups = append(out.Groups, presenters.Group(g))
+	}
+
+	span.SetAttributes(attribute.Int("group.count", len(out.Groups)))
+
+	return out, nil
+}
+
+// DeleteGroup implements [collection.CollectionServiceServer].
+func (s *collectionServer) DeleteGroup(ctx context.Context, req *collection.DeleteGroupRequest) (*emptypb.Empty, error) {
+	ctx, span := tracer.Start(ctx, "DeleteGroup",
+		trace.WithAttributes(
+			attribute.String("group.id", req.GroupId),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.GroupId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid group id")
+		return nil, tberrors.ErrGroupNotFound
+	}
+
+	if err := s.authorizeGroup(ctx, authz.ActionManageGroup, &id, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "delete group failed")
+		return nil, err
+	}
+
+	err = s.store.DeleteGroup(ctx, &id, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "delete group failed")
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// AddGroupMember implements [collection.CollectionServiceServer].
+func (s *collectionServer) AddGroupMember(ctx context.Context, req *collection.AddGroupMemberRequest) (*collection.GroupMember, error) {
+	ctx, span := tracer.Start(ctx, "AddGroupMember",
+		trace.WithAttributes(
+			attribute.String("group.id", req.GroupId),
+			attribute.String("member.id", req.UserId),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.GroupId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid group id")
+		return nil, tberrors.ErrGroupNotFound
+	}
+
+	member, err := uuid.Parse(req.UserId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid member")
+		return nil, tberrors.ErrInvalidGrantee
+	}
+
+	if err := s.authorizeGroup(ctx, authz.ActionManageGroup, &id, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "add group member failed")
+		return nil, err
+	}
+
+	out, err := s.store.AddGroupMember(ctx, &id, &member, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "add group member failed")
+		return nil, err
+	}
+
+	return presenters.GroupMember(out), nil
+}
+
+// RemoveGroupMember implements [collection.CollectionServiceServer].
+func (s *collectionServer) RemoveGroupMember(ctx context.Context, req *collection.RemoveGroupMemberRequest) (*emptypb.Empty, error) {
+	ctx, span := tracer.Start(ctx, "RemoveGroupMember",
+		trace.WithAttributes(
+			attribute.String("group.id", req.GroupId),
+			attribute.String("member.id", req.UserId),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.GroupId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid group id")
+		return nil, tberrors.ErrGroupNotFound
+	}
+
+	member, err := uuid.Parse(req.UserId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid member")
+		return nil, tberrors.ErrMemberNotFound
+	}
+
+	if err := s.authorizeGroup(ctx, authz.ActionManageGroup, &id, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "remove group member failed")
+		return nil, err
+	}
+
+	err = s.store.RemoveGroupMember(ctx, &id, &member, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "remove group member failed")
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListGroupMembers implements [collection.CollectionServiceServer].
+func (s *collectionServer) ListGroupMembers(ctx context.Context, req *collection.ListGroupMembersRequest) (*collection.ListGroupMembersResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListGroupMembers",
+		trace.WithAttributes(
+			attribute.String("group.id", req.GroupId),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.GroupId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid group id")
+		return nil, tberrors.ErrGroupNotFound
+	}
+
+	if err := s.authorizeGroup(ctx, authz.ActionViewGroup, &id, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list group members failed")
+		return nil, err
+	}
+
+	found, err := s.store.ListGroupMembers(ctx, &id, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list group members failed")
+		return nil, err
+	}
+
+	out := &collection.ListGroupMembersResponse{}
+	for _, m := range found {
+		out.Members = append(out.Members, presenters.GroupMember(m))
+	}
+
+	span.SetAttributes(attribute.Int("group.member.count", len(out.Members)))
+
+	return out, nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"testbert/server/datastore"
+)
+
+// StartPurger Periodically removes collections that have been in the trash for longer than trashRetention and
+// events that were sent from the outbox longer than outboxRetention ago, until ctx is done. The returned channel
+// is closed once the purger has stopped
+func StartPurger(ctx context.Context, store datastore.TestBertDatastore, trashRetention, outboxRetention, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			purgeTrash(ctx, store, trashRetention)
+			purgeOutbox(ctx, store, outboxRetention)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return done
+}
+
+func purgeTrash(ctx context.Context, store datastore.TrashStore, retention time.Duration) {
+	ctx, span := tracer.Start(ctx, "PurgeTrash")
+	defer span.End()
+
+	count, err := store.PurgeTrash(ctx, time.Now().Add(-retention))
+	if err != nil {
+		span.RecordError(err)
+		log.Printf("error purging trash: %v", err)
+		return
+	}
+
+	if count > 0 {
+		log.Printf("purged %d collections from the trash", count)
+	}
+}
+
+func purgeOutbox(ctx context.Context, store datastore.OutboxStore, retention time.Duration) {
+	ctx, span := tracer.Start(ctx, "PurgeSentEvents")
+	defer span.End()
+
+	count, err := store.PurgeSentEvents(ctx, time.Now().Add(-retention))
+	if err != nil {
+		span.RecordError(err)
+		log.Printf("error purging sent events: %v", err)
+		return
+	}
+
+	if count > 0 {
+		log.Printf("purged %d sent events from the outbox", count)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"testbert/server/datastore"
+	"testbert/server/events"
+	"testbert/server/model"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const relayBatchSize = 100
+
+// StartEventRelay Periodically delivers access events waiting in the outbox to publisher, until ctx is done.
+// Events are only marked sent once the publisher has accepted them, so they may be delivered more than once.
+// The returned channel is closed once the relay has stopped
+func StartEventRelay(ctx context.Context, store datastore.OutboxStore, publisher events.EventPublisher, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			relayEvents(ctx, store, publisher)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return done
+}
+
+func relayEvents(ctx context.Context, store datastore.OutboxStore, publisher events.EventPublisher) {
+	ctx, span := tracer.Start(ctx, "RelayEvents")
+	defer span.End()
+
+	total := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("event.count", total))
+	}()
+
+	// keep going while full batches are waiting so a backlog drains without waiting for the next tick
+	for ctx.Err() == nil {
+		sent, err := store.RelayEvents(ctx, relayBatchSize, func(e *model.AccessEvent) error {
+			return publisher.Publish(ctx, e)
+		})
+		total += sent
+		if err != nil {
+			span.RecordError(err)
+			log.Printf("error relaying events: %v", err)
+			return
+		}
+
+		if sent < relayBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+
+	"testbert/protobuf/collection"
+	"testbert/server/authz"
+	"testbert/server/model"
+	"testbert/server/presenters"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ListRevisions implements [collection.CollectionServiceServer].
+func (s *collectionServer) ListRevisions(ctx context.Context, req *collection.ListRevisionsRequest) (*collection.ListRevisionsResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListRevisions",
+		trace.WithAttributes(
+			attribute.String("collection.id", req.CollectionId),
+			attribute.Int("page.size", int(req.PageSize)),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.CollectionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid collection id")
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	pageSize, err := normalizePageSize(req.PageSize)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid page size")
+		return nil, err
+	}
+
+	before, err := decodeSequencePageToken(req.PageToken)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid page token")
+		return nil, tberrors.ErrInvalidPageToken
+	}
+
+	// Fetch one extra to find out if there is another page
+	if _, err := s.authorize(ctx, authz.ActionView, &id, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list revisions failed")
+		return nil, err
+	}
+
+	found, err := s.store.ListRevisions(ctx, &id, before, pageSize+1, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list revisions failed")
+		return nil, err
+	}
+
+	out := &collection.ListRevisionsResponse{}
+	if len(found) > pageSize {
+		found = found[:pageSize]
+		out.NextPageToken = encodeSequencePageToken(found[pageSize-1].Revision)
+	}
+
+	for _, r := range found {
+		out.Revisions = append(out.Revisions, presenters.Revision(r))
+	}
+
+	span.SetAttributes(attribute.Int("revision.count", len(out.Revisions)))
+
+	return out, nil
+}
+
+// GetCollectionRevision implements [collection.CollectionServiceServer].
+func (s *collectionServer) GetCollectionRevision(ctx context.Context, req *collection.GetCollectionRevisionRequest) (*collection.Revision, error) {
+	ctx, span := tracer.Start(ctx, "GetCollectionRevision",
+		trace.WithAttributes(
+			attribute.String("collection.id", req.CollectionId),
+			attribute.Int64("collection.revision", req.Revision),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.CollectionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid collection id")
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	if _, err := s.authorize(ctx, authz.ActionView, &id, user, org); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "get revision failed")
+		return nil, err
+	}
+
+	out, err := s.store.GetRevision(ctx, &id, req.Revision, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "get revision failed")
+		return nil, err
+	}
+
+	s.publishEvent(ctx, model.NewAccessEvent(ctx, model.ActionReadRevision, id.String(), user, org))
+	return presenters.Revision(out), nil
+}
+
+// RestoreCollectionRevision implements [collection.CollectionServiceServer].
+func (s *collectionServer) RestoreCollectionRevision(ctx context.Context, req *collection.RestoreCollectionRevisionRequest) (*collection.Collection, error) {
+	ctx, span := tracer.Start(ctx, "RestoreCollectionRevision",
+		trace.WithAttributes(
+			attribute.String("collection.id", req.CollectionId),
+			attribute.Int64("collection.revision", req.Revision),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.CollectionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid collection id")
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	version, err := parseEtag(req.Etag)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid etag")
+		return nil, tberrors.ErrEtagMismatch
+	}
+
+	out, err := s.store.RestoreRevision(ctx, &id, req.Revision, version, user, org, s.check(ctx, authz.ActionEdit, user, org))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "restore revision failed")
+		return nil, err
+	}
+
+	return presenters.Collection(out), nil
+}
+
+// encodeSequencePageToken Page token for lists ordered by a descending sequence number, such as revisions
+// and audit events
+func encodeSequencePageToken(before int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(before, 10)))
+}
+
+func decodeSequencePageToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+
+	before, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if before < 1 {
+		return 0, strconv.ErrRange
+	}
+
+	return before, nil
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+
+	"testbert/protobuf/collection"
+	"testbert/server/authz"
+	"testbert/server/model"
+	"testbert/server/presenters"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TransferOwnership implements [collection.CollectionServiceServer].
+func (s *collectionServer) TransferOwnership(ctx context.Context, req *collection.TransferOwnershipRequest) (*collection.TransferOwnershipResponse, error) {
+	ctx, span := tracer.Start(ctx, "TransferOwnership",
+		trace.WithAttributes(
+			attribute.String("collection.id", req.CollectionId),
+			attribute.String("transfer.to_user_id", req.NewOwnerId),
+			attribute.String("transfer.to_org_id", req.NewOrgId),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.CollectionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid collection id")
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	// The store keeps the collection in its org when no new org is given
+	t := &model.Transfer{CollectionID: id}
+	if t.ToUserID, err = uuid.Parse(req.NewOwnerId); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid new owner")
+		return nil, tberrors.ErrInvalidTransfer
+	}
+	if req.NewOrgId != "" {
+		if t.ToOrgID, err = uuid.Parse(req.NewOrgId); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid new org")
+			return nil, tberrors.ErrInvalidTransfer
+		}
+	}
+
+	found, err := s.store.TransferOwnership(ctx, t, user, org, s.check(ctx, authz.ActionManage, user, org))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "transfer ownership failed")
+		return nil, err
+	}
+
+	out := &collection.TransferOwnershipResponse{Pending: found == nil}
+	if found != nil {
+		out.Collection = presenters.Collection(found)
+	}
+
+	span.SetAttributes(attribute.Bool("transfer.pending", out.Pending))
+
+	return out, nil
+}
+
+// AcceptTransfer implements [collection.CollectionServiceServer].
+func (s *collectionServer) AcceptTransfer(ctx context.Context, req *collection.AcceptTransferRequest) (*collection.Collection, error) {
+	ctx, span := tracer.Start(ctx, "AcceptTransfer",
+		trace.WithAttributes(
+			attribute.String("collection.id", req.CollectionId),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.CollectionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid collection id")
+		return nil, tberrors.ErrTransferNotFound
+	}
+
+	out, err := s.store.AcceptTransfer(ctx, &id, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "accept transfer failed")
+		return nil, err
+	}
+
+	return presenters.Collection(out), nil
+}
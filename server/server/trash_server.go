@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+
+	"testbert/protobuf/collection"
+	"testbert/server/authz"
+	"testbert/server/model"
+	"testbert/server/presenters"
+	"testbert/server/tberrors"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ListTrash implements [collection.CollectionServiceServer].
+func (s *collectionServer) ListTrash(ctx context.Context, req *collection.ListTrashRequest) (*collection.ListTrashResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListTrash",
+		trace.WithAttributes(
+			attribute.Int("page.size", int(req.PageSize)),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	pageSize, err := normalizePageSize(req.PageSize)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid page size")
+		return nil, err
+	}
+
+	after, err := decodePageToken(req.PageToken)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid page token")
+		return nil, tberrors.ErrInvalidPageToken
+	}
+
+	list := func(after *uuid.UUID, limit int) ([]*model.Collection, error) {
+		return s.store.ListTrash(ctx, after, limit, user, org)
+	}
+
+	// Restoring needs the same access as editing. Fetch one extra to find out if there is another page
+	found, err := s.allowed(ctx, authz.ActionEdit, list, after, pageSize+1, user, org)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list trash failed")
+		return nil, err
+	}
+
+	out := &collection.ListTrashResponse{}
+	if len(found) > pageSize {
+		found = found[:pageSize]
+		out.NextPageToken = encodePageToken(&found[pageSize-1].ID)
+	}
+
+	for _, c := range found {
+		out.Collections = append(out.Collections, presenters.Collection(c))
+	}
+
+	span.SetAttributes(attribute.Int("collection.count", len(out.Collections)))
+
+	return out, nil
+}
+
+// RestoreCollection implements [collection.CollectionServiceServer].
+func (s *collectionServer) RestoreCollection(ctx context.Context, req *collection.RestoreCollectionRequest) (*collection.Collection, error) {
+	ctx, span := tracer.Start(ctx, "RestoreCollection",
+		trace.WithAttributes(
+			attribute.String("collection.id", req.CollectionId),
+		))
+	defer span.End()
+
+	user, org, err := getLoggedInUserAndOrg(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", user.String()),
+		attribute.String("org.id", org.String()),
+	)
+
+	id, err := uuid.Parse(req.CollectionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid collection id")
+		return nil, tberrors.ErrCollectionNotFound
+	}
+
+	out, err := s.store.RestoreCollection(ctx, &id, user, org, s.check(ctx, authz.ActionEdit, user, org))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "restore collection failed")
+		return nil, err
+	}
+
+	return presenters.Collection(out), nil
+}
@@ -0,0 +1,63 @@
+// Package sharetoken Generation and hashing of collection sharing tokens
+package sharetoken
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	tokenBytes = 32
+	idBytes    = 8
+	// fingerprintLength Hex characters of the hash kept in a fingerprint
+	fingerprintLength = 16
+)
+
+// Hasher Computes the keyed hash sharing tokens are stored under
+type Hasher struct {
+	key []byte
+}
+
+func NewHasher(secret string) *Hasher {
+	return &Hasher{
+		key: []byte(secret),
+	}
+}
+
+// Hash returns the hex encoded HMAC-SHA256 of a token
+func (h *Hasher) Hash(token string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Fingerprint returns a short prefix of the token hash that is safe to log, it identifies the token
+// without revealing it and matches the start of the stored hash
+func (h *Hasher) Fingerprint(token string) string {
+	return FingerprintFromHash(h.Hash(token))
+}
+
+// FingerprintFromHash returns the fingerprint of the token a stored hash was made from
+func FingerprintFromHash(hash string) string {
+	return hash[:min(fingerprintLength, len(hash))]
+}
+
+// NewToken returns a new random secret token
+func NewToken() string {
+	return base64.RawURLEncoding.EncodeToString(random(tokenBytes))
+}
+
+// NewID returns a new random short identifier that can be shown in place of a token
+func NewID() string {
+	return hex.EncodeToString(random(idBytes))
+}
+
+func random(n int) []byte {
+	b := make([]byte, n)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(b)
+	return b
+}
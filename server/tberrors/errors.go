@@ -9,7 +9,25 @@ import (
 var (
 	ErrCollectionNotFound = status.Error(codes.NotFound, "collection not found")
 	ErrTokenNotFound      = status.Error(codes.NotFound, "sharing token not found")
+	ErrRevisionNotFound   = status.Error(codes.NotFound, "revision not found")
 	ErrUnauthorized       = status.Error(codes.Unauthenticated, "unauthorized")
 	ErrInternal           = status.Error(codes.Internal, "internal error")
 	ErrRateLimited        = status.Error(codes.ResourceExhausted, "rate limited")
+	ErrInvalidPageToken   = status.Error(codes.InvalidArgument, "invalid page token")
+	ErrInvalidPageSize    = status.Error(codes.InvalidArgument, "invalid page size")
+	ErrEtagMismatch       = status.Error(codes.Aborted, "etag does not match current collection version")
+	ErrInvalidUpdateMask  = status.Error(codes.InvalidArgument, "invalid update mask")
+	ErrInvalidExpiry      = status.Error(codes.InvalidArgument, "expiry must be in the future")
+	ErrInvalidMaxUses     = status.Error(codes.InvalidArgument, "max uses cannot be negative")
+	ErrInvalidAuditFilter = status.Error(codes.InvalidArgument, "invalid audit log filter")
+	ErrGrantNotFound      = status.Error(codes.NotFound, "grant not found")
+	ErrInvalidGrantee     = status.Error(codes.InvalidArgument, "invalid grantee")
+	ErrGroupNotFound      = status.Error(codes.NotFound, "group not found")
+	ErrMemberNotFound     = status.Error(codes.NotFound, "group member not found")
+	ErrInvalidGroupName   = status.Error(codes.InvalidArgument, "invalid group name")
+	ErrGroupExists        = status.Error(codes.AlreadyExists, "group already exists")
+	ErrInvalidTransfer    = status.Error(codes.InvalidArgument, "invalid ownership transfer")
+	ErrTransferNotFound   = status.Error(codes.NotFound, "transfer not found")
+	ErrAPIKeyNotFound     = status.Error(codes.NotFound, "api key not found")
+	ErrInvalidAPIKeyName  = status.Error(codes.InvalidArgument, "invalid api key name")
 )
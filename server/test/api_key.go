@@ -0,0 +1,130 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"testbert/protobuf/collection"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testAPIKeys(t *testing.T, tc *TestClient) {
+	admin := uuid.New()
+	member := uuid.New()
+	orgOne := uuid.New()
+	orgTwo := uuid.New()
+
+	admins := tc.AsOrgAdmin()
+
+	t.Run("role is required", func(t *testing.T) {
+		_, err := tc.CreateAPIKey("nightly export", &member, &orgOne)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = tc.ListAPIKeys(&member, &orgOne)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("name is required", func(t *testing.T) {
+		_, err := admins.CreateAPIKey("  ", &admin, &orgOne)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	key, err := admins.CreateAPIKey("nightly export", &admin, &orgOne)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(key.Key, "tbk_"), "keys can be recognized")
+	assert.Equal(t, "nightly export", key.Name)
+	assert.Equal(t, orgOne.String(), key.OrgId)
+	assert.Equal(t, admin.String(), key.CreatedBy)
+	assert.Nil(t, key.LastUsedAt)
+
+	svc := tc.WithAPIKey(key.Key)
+
+	t.Run("list never returns the key", func(t *testing.T) {
+		out, err := admins.ListAPIKeys(&admin, &orgOne)
+		if assert.NoError(t, err) && assert.Len(t, out.ApiKeys, 1) {
+			assert.Equal(t, key.ApiKeyId, out.ApiKeys[0].ApiKeyId)
+			assert.Empty(t, out.ApiKeys[0].Key)
+		}
+
+		out, err = admins.ListAPIKeys(&admin, &orgTwo)
+		if assert.NoError(t, err) {
+			assert.Empty(t, out.ApiKeys)
+		}
+	})
+
+	t.Run("service principal can call the api", func(t *testing.T) {
+		c, err := svc.CreateCollection(&collection.Collection{
+			CollectionData: "exported",
+		}, nil, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, key.ApiKeyId, c.OwnerId)
+
+		_, err = svc.GetCollection(nil, nil, c.CollectionId)
+		assert.NoError(t, err)
+
+		out, err := svc.GetAuditLog(&collection.GetAuditLogRequest{CollectionId: c.CollectionId}, nil, nil)
+		if assert.NoError(t, err) && assert.NotEmpty(t, out.Events) {
+			for _, e := range out.Events {
+				assert.Equal(t, key.ApiKeyId, e.UserId)
+				assert.True(t, e.ServicePrincipal)
+			}
+		}
+
+		keys, err := admins.ListAPIKeys(&admin, &orgOne)
+		if !assert.NoError(t, err) || !assert.Len(t, keys.ApiKeys, 1) || !assert.NotNil(t, keys.ApiKeys[0].LastUsedAt) {
+			return
+		}
+
+		// another use straight away isn't recorded
+		_, err = svc.GetCollection(nil, nil, c.CollectionId)
+		assert.NoError(t, err)
+		again, err := admins.ListAPIKeys(&admin, &orgOne)
+		if assert.NoError(t, err) && assert.Len(t, again.ApiKeys, 1) {
+			assert.Equal(t, keys.ApiKeys[0].LastUsedAt.AsTime(), again.ApiKeys[0].LastUsedAt.AsTime())
+		}
+	})
+
+	t.Run("service principal can't manage keys", func(t *testing.T) {
+		_, err := svc.CreateAPIKey("escalated", nil, nil)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		err = svc.RevokeAPIKey(key.ApiKeyId, nil, nil)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("unknown key is rejected", func(t *testing.T) {
+		_, err := tc.WithAPIKey("not-a-key").ListCollections(&collection.ListCollectionsRequest{}, nil, nil)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("admin of another org can't revoke", func(t *testing.T) {
+		err := admins.RevokeAPIKey(key.ApiKeyId, &admin, &orgTwo)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		err = admins.RevokeAPIKey("not-a-uuid", &admin, &orgOne)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("revoked key is rejected", func(t *testing.T) {
+		assert.NoError(t, admins.RevokeAPIKey(key.ApiKeyId, &admin, &orgOne))
+
+		_, err := svc.ListCollections(&collection.ListCollectionsRequest{}, nil, nil)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		err = admins.RevokeAPIKey(key.ApiKeyId, &admin, &orgOne)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		out, err := admins.ListAPIKeys(&admin, &orgOne)
+		if assert.NoError(t, err) {
+			assert.Empty(t, out.ApiKeys)
+		}
+	})
+}
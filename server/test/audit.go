@@ -0,0 +1,163 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"testbert/protobuf/collection"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func testAuditLog(t *testing.T, tc *TestClient) {
+	userOne := uuid.New()
+	userTwo := uuid.New()
+	orgOne := uuid.New()
+	orgTwo := uuid.New()
+
+	start := time.Now().Add(-time.Minute)
+
+	c := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "audited",
+		OrgView:        true,
+	}, &userOne, &orgOne)
+
+	_, err := tc.GetCollection(&userTwo, &orgOne, c.CollectionId)
+	assert.NoError(t, err)
+
+	_, err = tc.UpdateCollection(&collection.Collection{
+		CollectionId:   c.CollectionId,
+		CollectionData: "audited again",
+		OrgView:        true,
+	}, &userOne, &orgOne)
+	assert.NoError(t, err)
+
+	tok := mustCreateShareToken(t, tc, c.CollectionId, &userOne, &orgOne)
+	_, err = tc.GetSharedCollection(tok.Token)
+	assert.NoError(t, err)
+	assert.NoError(t, tc.DeleteShareToken(tok.Token, &userOne, &orgOne))
+
+	out, err := tc.GetAuditLog(&collection.GetAuditLogRequest{CollectionId: c.CollectionId}, &userOne, &orgOne)
+	if !assert.NoError(t, err) {
+		return
+	}
+	all := out.Events
+
+	t.Run("owner sees every access newest first", func(t *testing.T) {
+		actions := []string{}
+		for _, e := range all {
+			actions = append(actions, e.Action)
+			assert.Equal(t, c.CollectionId, e.CollectionId)
+		}
+		assert.Equal(t, []string{"revoke", "readShared", "share", "update", "read", "create"}, actions)
+	})
+
+	t.Run("events record who accessed the collection", func(t *testing.T) {
+		if !assert.Len(t, all, 6) {
+			return
+		}
+		assert.Equal(t, userTwo.String(), all[4].UserId)
+		assert.Equal(t, orgOne.String(), all[4].OrgId)
+
+		// anonymous reads are identified by the token fingerprint only
+		assert.Empty(t, all[1].UserId)
+		assert.NotEmpty(t, all[1].TokenFingerprint)
+		assert.NotContains(t, all[1].TokenFingerprint, tok.Token)
+		assert.Equal(t, all[2].TokenFingerprint, all[1].TokenFingerprint)
+		assert.Equal(t, all[2].TokenFingerprint, all[0].TokenFingerprint)
+		assert.Equal(t, tok.TokenId, all[0].TokenId)
+	})
+
+	t.Run("events are paged", func(t *testing.T) {
+		actions := []string{}
+		token := ""
+		for {
+			out, err := tc.GetAuditLog(&collection.GetAuditLogRequest{
+				CollectionId: c.CollectionId,
+				PageSize:     4,
+				PageToken:    token,
+			}, &userOne, &orgOne)
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, e := range out.Events {
+				actions = append(actions, e.Action)
+			}
+			if out.NextPageToken == "" {
+				break
+			}
+			token = out.NextPageToken
+		}
+		assert.Equal(t, []string{"revoke", "readShared", "share", "update", "read", "create"}, actions)
+	})
+
+	t.Run("filter by action", func(t *testing.T) {
+		out, err := tc.GetAuditLog(&collection.GetAuditLogRequest{
+			CollectionId: c.CollectionId,
+			Actions:      []string{"read", "readShared"},
+		}, &userOne, &orgOne)
+		if !assert.NoError(t, err) || !assert.Len(t, out.Events, 2) {
+			return
+		}
+		assert.Equal(t, "readShared", out.Events[0].Action)
+		assert.Equal(t, "read", out.Events[1].Action)
+	})
+
+	t.Run("filter by time range", func(t *testing.T) {
+		out, err := tc.GetAuditLog(&collection.GetAuditLogRequest{
+			CollectionId: c.CollectionId,
+			Since:        timestamppb.New(start),
+			Until:        timestamppb.New(time.Now().Add(time.Minute)),
+		}, &userOne, &orgOne)
+		if assert.NoError(t, err) {
+			assert.Len(t, out.Events, 6)
+		}
+
+		out, err = tc.GetAuditLog(&collection.GetAuditLogRequest{
+			CollectionId: c.CollectionId,
+			Until:        timestamppb.New(start),
+		}, &userOne, &orgOne)
+		if assert.NoError(t, err) {
+			assert.Empty(t, out.Events)
+		}
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		_, err := tc.GetAuditLog(&collection.GetAuditLogRequest{
+			CollectionId: c.CollectionId,
+			Actions:      []string{"teleport"},
+		}, &userOne, &orgOne)
+		assert.Error(t, err)
+
+		_, err = tc.GetAuditLog(&collection.GetAuditLogRequest{
+			CollectionId: c.CollectionId,
+			Since:        timestamppb.New(time.Now()),
+			Until:        timestamppb.New(start),
+		}, &userOne, &orgOne)
+		assert.Error(t, err)
+	})
+
+	t.Run("org member who can read cannot audit", func(t *testing.T) {
+		_, err := tc.GetAuditLog(&collection.GetAuditLogRequest{CollectionId: c.CollectionId}, &userTwo, &orgOne)
+		assert.Error(t, err)
+	})
+
+	t.Run("user from different org cannot audit", func(t *testing.T) {
+		_, err := tc.GetAuditLog(&collection.GetAuditLogRequest{CollectionId: c.CollectionId}, &userTwo, &orgTwo)
+		assert.Error(t, err)
+	})
+
+	t.Run("trashed collection can still be audited", func(t *testing.T) {
+		assert.NoError(t, tc.DeleteCollection(c.CollectionId, &userOne, &orgOne))
+
+		out, err := tc.GetAuditLog(&collection.GetAuditLogRequest{
+			CollectionId: c.CollectionId,
+			PageSize:     1,
+		}, &userOne, &orgOne)
+		if assert.NoError(t, err) && assert.Len(t, out.Events, 1) {
+			assert.Equal(t, "delete", out.Events[0].Action)
+		}
+	})
+}
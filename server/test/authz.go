@@ -0,0 +1,261 @@
+package test
+
+import (
+	"slices"
+	"testing"
+
+	"testbert/protobuf/collection"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// principal How the user making requests in an authorization case is related to the collection
+type principal int
+
+const (
+	principalOwner principal = iota
+	principalMember
+	principalOutsider
+	principalGrantee
+	principalGroupMember
+	principalOrgAdmin
+	principalOtherOrgAdmin
+)
+
+// testAuthorization checks every datastore makes the same decisions, each case gets its own orgs so lists
+// only contain its collection
+func testAuthorization(t *testing.T, tc *TestClient) {
+	tests := []struct {
+		name      string
+		principal principal
+		// in The org flags of the collection
+		in *collection.Collection
+		// grant The grant given to the grantee or their group
+		grant *collection.GrantAccessRequest
+
+		view, edit, share, manage bool
+		// revoke Whether the principal can revoke the owner's share token
+		revoke bool
+	}{
+		{
+			name:      "owner",
+			principal: principalOwner,
+			in:        &collection.Collection{},
+			view:      true, edit: true, share: true, manage: true, revoke: true,
+		},
+		{
+			name:      "member of a private collection's org",
+			principal: principalMember,
+			in:        &collection.Collection{},
+		},
+		{
+			name:      "member with org view",
+			principal: principalMember,
+			in:        &collection.Collection{OrgView: true},
+			view:      true,
+		},
+		{
+			name:      "member with org edit",
+			principal: principalMember,
+			in:        &collection.Collection{OrgView: true, OrgEdit: true},
+			view:      true, edit: true,
+		},
+		{
+			name:      "member with org share",
+			principal: principalMember,
+			in:        &collection.Collection{OrgView: true, OrgShare: true},
+			view:      true, share: true, revoke: true,
+		},
+		{
+			// org_edit and revoking tokens with org_share don't need the collection to be visible
+			name:      "member with org edit and share but not org view",
+			principal: principalMember,
+			in:        &collection.Collection{OrgEdit: true, OrgShare: true},
+			edit:      true, revoke: true,
+		},
+		{
+			name:      "user in another org",
+			principal: principalOutsider,
+			in:        &collection.Collection{OrgView: true, OrgEdit: true, OrgShare: true},
+		},
+		{
+			name:      "grantee",
+			principal: principalGrantee,
+			in:        &collection.Collection{},
+			grant:     &collection.GrantAccessRequest{},
+			view:      true,
+		},
+		{
+			name:      "grantee who can edit",
+			principal: principalGrantee,
+			in:        &collection.Collection{},
+			grant:     &collection.GrantAccessRequest{CanEdit: true},
+			view:      true, edit: true,
+		},
+		{
+			name:      "grantee who can share",
+			principal: principalGrantee,
+			in:        &collection.Collection{},
+			grant:     &collection.GrantAccessRequest{CanShare: true},
+			view:      true, share: true, revoke: true,
+		},
+		{
+			name:      "group member who can edit",
+			principal: principalGroupMember,
+			in:        &collection.Collection{},
+			grant:     &collection.GrantAccessRequest{CanEdit: true},
+			view:      true, edit: true,
+		},
+		{
+			name:      "org admin",
+			principal: principalOrgAdmin,
+			in:        &collection.Collection{},
+			view:      true, edit: true, share: true, manage: true, revoke: true,
+		},
+		{
+			name:      "admin of another org",
+			principal: principalOtherOrgAdmin,
+			in:        &collection.Collection{OrgView: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner := uuid.New()
+			orgOne := uuid.New()
+			orgTwo := uuid.New()
+
+			tt.in.CollectionData = tt.name
+			c := mustCreateCollection(t, tc, tt.in, &owner, &orgOne)
+			tok := mustCreateShareToken(t, tc, c.CollectionId, &owner, &orgOne)
+
+			client, user, org := tc, uuid.New(), orgOne
+			switch tt.principal {
+			case principalOwner:
+				user = owner
+			case principalOutsider, principalGrantee:
+				org = orgTwo
+			case principalGroupMember:
+				group, err := tc.CreateGroup("editors", &owner, &orgOne)
+				if !assert.NoError(t, err) {
+					return
+				}
+				_, err = tc.AddGroupMember(group.GroupId, &user, &owner, &orgOne)
+				if !assert.NoError(t, err) {
+					return
+				}
+				tt.grant.GroupId = group.GroupId
+			case principalOrgAdmin:
+				client = tc.AsOrgAdmin()
+			case principalOtherOrgAdmin:
+				client, org = tc.AsOrgAdmin(), orgTwo
+			}
+
+			if tt.grant != nil {
+				tt.grant.CollectionId = c.CollectionId
+				if tt.grant.GroupId == "" {
+					tt.grant.UserId = user.String()
+				}
+				_, err := tc.GrantAccess(tt.grant, &owner, &orgOne)
+				if !assert.NoError(t, err) {
+					return
+				}
+			}
+
+			_, err := client.GetCollection(&user, &org, c.CollectionId)
+			assert.Equal(t, wantCode(tt.view, tt.view), status.Code(err), "get")
+
+			list, err := client.ListCollections(&collection.ListCollectionsRequest{}, &user, &org)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.view, slices.ContainsFunc(list.Collections, func(l *collection.Collection) bool {
+					return l.CollectionId == c.CollectionId
+				}), "list")
+			}
+
+			_, err = client.ListRevisions(&collection.ListRevisionsRequest{CollectionId: c.CollectionId}, &user, &org)
+			assert.Equal(t, wantCode(tt.view, tt.view), status.Code(err), "list revisions")
+
+			_, err = client.PatchCollection(&collection.Collection{
+				CollectionId: c.CollectionId,
+				Title:        "patched",
+			}, []string{"title"}, &user, &org)
+			assert.Equal(t, wantCode(tt.edit, tt.view), status.Code(err), "edit")
+
+			_, err = client.ShareCollection(c.CollectionId, &user, &org)
+			assert.Equal(t, wantCode(tt.share, tt.view), status.Code(err), "share")
+
+			_, err = client.ListShareTokens(c.CollectionId, &user, &org)
+			assert.Equal(t, wantCode(tt.share, tt.view), status.Code(err), "list share tokens")
+
+			_, err = client.ListGrants(c.CollectionId, &user, &org)
+			assert.Equal(t, wantCode(tt.manage, tt.view), status.Code(err), "list grants")
+
+			// tokens the user can't revoke are never revealed
+			err = client.DeleteShareTokenByID(tok.TokenId, &user, &org)
+			assert.Equal(t, wantCode(tt.revoke, false), status.Code(err), "revoke share token")
+
+			_, err = client.GetAuditLog(&collection.GetAuditLogRequest{CollectionId: c.CollectionId}, &user, &org)
+			assert.Equal(t, wantCode(tt.manage, tt.view), status.Code(err), "audit log")
+
+			_, err = client.GrantAccess(&collection.GrantAccessRequest{
+				CollectionId: c.CollectionId,
+				UserId:       uuid.NewString(),
+			}, &user, &org)
+			assert.Equal(t, wantCode(tt.manage, tt.view), status.Code(err), "grant access")
+
+			if !assert.NoError(t, tc.DeleteCollection(c.CollectionId, &owner, &orgOne)) {
+				return
+			}
+
+			trash, err := client.ListTrash(&collection.ListTrashRequest{}, &user, &org)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.edit, len(trash.Collections) == 1, "list trash")
+			}
+
+			_, err = client.RestoreCollection(c.CollectionId, &user, &org)
+			assert.Equal(t, wantCode(tt.edit, tt.view), status.Code(err), "restore")
+		})
+	}
+
+	t.Run("token creator can't revoke after losing access", func(t *testing.T) {
+		owner := uuid.New()
+		sharer := uuid.New()
+		org := uuid.New()
+
+		c := mustCreateCollection(t, tc, &collection.Collection{CollectionData: "shared"}, &owner, &org)
+		_, err := tc.GrantAccess(&collection.GrantAccessRequest{
+			CollectionId: c.CollectionId,
+			UserId:       sharer.String(),
+			CanShare:     true,
+		}, &owner, &org)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		tok := mustCreateShareToken(t, tc, c.CollectionId, &sharer, &org)
+		if !assert.NoError(t, tc.RevokeAccess(c.CollectionId, &sharer, &owner, &org)) {
+			return
+		}
+
+		_, err = tc.GetCollection(&sharer, &org, c.CollectionId)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		err = tc.DeleteShareTokenByID(tok.TokenId, &sharer, &org)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.NoError(t, tc.DeleteShareTokenByID(tok.TokenId, &owner, &org))
+	})
+}
+
+// wantCode The status a request should fail with, or OK when it is allowed
+func wantCode(allowed, visible bool) codes.Code {
+	switch {
+	case allowed:
+		return codes.OK
+	case visible:
+		return codes.Unauthenticated
+	default:
+		return codes.NotFound
+	}
+}
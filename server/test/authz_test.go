@@ -0,0 +1,89 @@
+package test
+
+import (
+	"testing"
+
+	"testbert/protobuf/collection"
+	"testbert/server/authz"
+	"testbert/server/config"
+	"testbert/server/events"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestAuthorization runs the authorization cases against the in memory datastore, TestIntegration runs them
+// against postgres
+func TestAuthorization(t *testing.T) {
+	cfg := &config.Configuration{
+		AuthSecret:       "testkey",
+		ShareTokenSecret: "testsharekey",
+	}
+
+	tc, _, closer := newMemServer(cfg, authz.Default{}, events.NopPublisher{})
+	defer closer()
+
+	testAuthorization(t, tc)
+}
+
+// ownersOnly Hides collections from everyone but their owners, whatever the org flags allow
+type ownersOnly struct {
+	authz.Default
+}
+
+func (p ownersOnly) Decide(s *authz.Subject, a authz.Action, r *authz.Resource) authz.Decision {
+	if r.Collection != nil && r.Collection.UserID != s.UserID {
+		return authz.Hide
+	}
+
+	return p.Default.Decide(s, a, r)
+}
+
+func TestCustomPolicy(t *testing.T) {
+	cfg := &config.Configuration{
+		AuthSecret:       "testkey",
+		ShareTokenSecret: "testsharekey",
+	}
+
+	tc, _, closer := newMemServer(cfg, ownersOnly{}, events.NopPublisher{})
+	defer closer()
+
+	owner := uuid.New()
+	member := uuid.New()
+	org := uuid.New()
+
+	open := &collection.Collection{OrgView: true, OrgEdit: true, OrgShare: true}
+	c := mustCreateCollection(t, tc, open, &owner, &org)
+	trashed := mustCreateCollection(t, tc, open, &owner, &org)
+	if !assert.NoError(t, tc.DeleteCollection(trashed.CollectionId, &owner, &org)) {
+		return
+	}
+
+	_, err := tc.GetCollection(&member, &org, c.CollectionId)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = tc.UpdateCollection(&collection.Collection{CollectionId: c.CollectionId}, &member, &org)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	list, err := tc.ListCollections(&collection.ListCollectionsRequest{}, &member, &org)
+	if assert.NoError(t, err) {
+		assert.Empty(t, list.Collections)
+	}
+
+	trash, err := tc.ListTrash(&collection.ListTrashRequest{}, &member, &org)
+	if assert.NoError(t, err) {
+		assert.Empty(t, trash.Collections)
+	}
+
+	list, err = tc.ListCollections(&collection.ListCollectionsRequest{}, &owner, &org)
+	if assert.NoError(t, err) && assert.Len(t, list.Collections, 1) {
+		assert.Equal(t, c.CollectionId, list.Collections[0].CollectionId)
+	}
+
+	trash, err = tc.ListTrash(&collection.ListTrashRequest{}, &owner, &org)
+	if assert.NoError(t, err) && assert.Len(t, trash.Collections, 1) {
+		assert.Equal(t, trashed.CollectionId, trash.Collections[0].CollectionId)
+	}
+}
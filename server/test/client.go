@@ -6,15 +6,22 @@ import (
 	"time"
 
 	"testbert/protobuf/collection"
+	"testbert/server/config"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type TestClient struct {
 	client collection.CollectionServiceClient
 	key    []byte
+	// role Sent as the role claim when set
+	role string
+	// apiKey Sent in place of a token when set
+	apiKey string
 }
 
 func NewClient(csc collection.CollectionServiceClient, secret string) *TestClient {
@@ -24,11 +31,27 @@ func NewClient(csc collection.CollectionServiceClient, secret string) *TestClien
 	}
 }
 
+// AsOrgAdmin Client whose calls are made with the org admin role
+func (tc *TestClient) AsOrgAdmin() *TestClient {
+	copied := *tc
+	copied.role = config.RoleOrgAdmin
+	return &copied
+}
+
+// WithAPIKey Client whose calls are made as the service principal of key, the user and org passed to its calls
+// are ignored
+func (tc *TestClient) WithAPIKey(key string) *TestClient {
+	copied := *tc
+	copied.apiKey = key
+	return &copied
+}
+
 func (tc *TestClient) CreateCollection(in *collection.Collection, user, org *uuid.UUID) (*collection.Collection, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	out, err := tc.client.CreateCollection(ctx, &collection.CreateCollectionRequest{
+		Title:          in.Title,
 		CollectionData: in.CollectionData,
 		OrgView:        in.OrgView,
 		OrgEdit:        in.OrgEdit,
@@ -61,10 +84,33 @@ func (tc *TestClient) UpdateCollection(in *collection.Collection, user, org *uui
 
 	out, err := tc.client.UpdateCollection(ctx, &collection.UpdateCollectionRequest{
 		CollectionId:   in.CollectionId,
+		Title:          in.Title,
 		CollectionData: in.CollectionData,
 		OrgView:        in.OrgView,
 		OrgEdit:        in.OrgEdit,
 		OrgShare:       in.OrgShare,
+		Etag:           in.Etag,
+	}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) PatchCollection(in *collection.Collection, paths []string, user, org *uuid.UUID) (*collection.Collection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.UpdateCollection(ctx, &collection.UpdateCollectionRequest{
+		CollectionId:   in.CollectionId,
+		Title:          in.Title,
+		CollectionData: in.CollectionData,
+		OrgView:        in.OrgView,
+		OrgEdit:        in.OrgEdit,
+		OrgShare:       in.OrgShare,
+		Etag:           in.Etag,
+		UpdateMask:     &fieldmaskpb.FieldMask{Paths: paths},
 	}, tc.withCredentials(user, org))
 	if err != nil {
 		return nil, err
@@ -86,6 +132,38 @@ func (tc *TestClient) ShareCollection(id string, user, org *uuid.UUID) (*collect
 	return out, nil
 }
 
+func (tc *TestClient) ShareCollectionWithLimits(id string, expiresAt *time.Time, maxUses int64, user, org *uuid.UUID) (*collection.ShareToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	req := &collection.CreateShareTokenRequest{
+		CollectionId: id,
+		MaxUses:      maxUses,
+	}
+	if expiresAt != nil {
+		req.ExpiresAt = timestamppb.New(*expiresAt)
+	}
+
+	out, err := tc.client.CreateShareToken(ctx, req, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (tc *TestClient) ListShareTokens(id string, user, org *uuid.UUID) (*collection.ListShareTokensResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.ListShareTokens(ctx, &collection.ListShareTokensRequest{
+		CollectionId: id,
+	}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (tc *TestClient) GetSharedCollection(id string) (*collection.Collection, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -111,6 +189,17 @@ func (tc *TestClient) DeleteShareToken(id string, user, org *uuid.UUID) error {
 	return err
 }
 
+func (tc *TestClient) DeleteShareTokenByID(id string, user, org *uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tc.client.RevokeShareToken(ctx, &collection.RevokeShareTokenRequest{
+		TokenId: id,
+	}, tc.withCredentials(user, org))
+
+	return err
+}
+
 func (tc *TestClient) DeleteCollection(id string, user, org *uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -122,26 +211,331 @@ func (tc *TestClient) DeleteCollection(id string, user, org *uuid.UUID) error {
 	return err
 }
 
+func (tc *TestClient) DeleteCollectionIfMatch(id, etag string, user, org *uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tc.client.DeleteCollection(ctx, &collection.DeleteCollectionRequest{
+		CollectionId: id,
+		Etag:         etag,
+	}, tc.withCredentials(user, org))
+
+	return err
+}
+
+func (tc *TestClient) ListCollections(req *collection.ListCollectionsRequest, user, org *uuid.UUID) (*collection.ListCollectionsResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.ListCollections(ctx, req, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) ListRevisions(req *collection.ListRevisionsRequest, user, org *uuid.UUID) (*collection.ListRevisionsResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.ListRevisions(ctx, req, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) GetRevision(id string, revision int64, user, org *uuid.UUID) (*collection.Revision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.GetCollectionRevision(ctx, &collection.GetCollectionRevisionRequest{
+		CollectionId: id,
+		Revision:     revision,
+	}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) RestoreRevision(id string, revision int64, user, org *uuid.UUID) (*collection.Collection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.RestoreCollectionRevision(ctx, &collection.RestoreCollectionRevisionRequest{
+		CollectionId: id,
+		Revision:     revision,
+	}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) ListTrash(req *collection.ListTrashRequest, user, org *uuid.UUID) (*collection.ListTrashResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.ListTrash(ctx, req, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) RestoreCollection(id string, user, org *uuid.UUID) (*collection.Collection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.RestoreCollection(ctx, &collection.RestoreCollectionRequest{
+		CollectionId: id,
+	}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) GetAuditLog(req *collection.GetAuditLogRequest, user, org *uuid.UUID) (*collection.GetAuditLogResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.GetAuditLog(ctx, req, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) GrantAccess(req *collection.GrantAccessRequest, user, org *uuid.UUID) (*collection.Grant, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.GrantAccess(ctx, req, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) RevokeAccess(id string, grantee, user, org *uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tc.client.RevokeAccess(ctx, &collection.RevokeAccessRequest{
+		CollectionId: id,
+		UserId:       grantee.String(),
+	}, tc.withCredentials(user, org))
+
+	return err
+}
+
+func (tc *TestClient) ListGrants(id string, user, org *uuid.UUID) (*collection.ListGrantsResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.ListGrants(ctx, &collection.ListGrantsRequest{CollectionId: id}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) CreateGroup(name string, user, org *uuid.UUID) (*collection.Group, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.CreateGroup(ctx, &collection.CreateGroupRequest{Name: name}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) ListGroups(user, org *uuid.UUID) (*collection.ListGroupsResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.ListGroups(ctx, &collection.ListGroupsRequest{}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) AddGroupMember(id string, member *uuid.UUID, user, org *uuid.UUID) (*collection.GroupMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.AddGroupMember(ctx, &collection.AddGroupMemberRequest{
+		GroupId: id,
+		UserId:  member.String(),
+	}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) ListGroupMembers(id string, user, org *uuid.UUID) (*collection.ListGroupMembersResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.ListGroupMembers(ctx, &collection.ListGroupMembersRequest{GroupId: id}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) DeleteGroup(id string, user, org *uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tc.client.DeleteGroup(ctx, &collection.DeleteGroupRequest{GroupId: id}, tc.withCredentials(user, org))
+
+	return err
+}
+
+func (tc *TestClient) RemoveGroupMember(id string, member, user, org *uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tc.client.RemoveGroupMember(ctx, &collection.RemoveGroupMemberRequest{
+		GroupId: id,
+		UserId:  member.String(),
+	}, tc.withCredentials(user, org))
+
+	return err
+}
+
+func (tc *TestClient) RevokeGroupAccess(id, group string, user, org *uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tc.client.RevokeAccess(ctx, &collection.RevokeAccessRequest{
+		CollectionId: id,
+		GroupId:      group,
+	}, tc.withCredentials(user, org))
+
+	return err
+}
+
+func (tc *TestClient) TransferOwnership(id string, owner, newOrg, user, org *uuid.UUID) (*collection.TransferOwnershipResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	req := &collection.TransferOwnershipRequest{
+		CollectionId: id,
+		NewOwnerId:   owner.String(),
+	}
+	if newOrg != nil {
+		req.NewOrgId = newOrg.String()
+	}
+
+	out, err := tc.client.TransferOwnership(ctx, req, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) AcceptTransfer(id string, user, org *uuid.UUID) (*collection.Collection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.AcceptTransfer(ctx, &collection.AcceptTransferRequest{CollectionId: id}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) CreateAPIKey(name string, user, org *uuid.UUID) (*collection.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.CreateAPIKey(ctx, &collection.CreateAPIKeyRequest{Name: name}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) ListAPIKeys(user, org *uuid.UUID) (*collection.ListAPIKeysResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := tc.client.ListAPIKeys(ctx, &collection.ListAPIKeysRequest{}, tc.withCredentials(user, org))
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (tc *TestClient) RevokeAPIKey(id string, user, org *uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tc.client.RevokeAPIKey(ctx, &collection.RevokeAPIKeyRequest{ApiKeyId: id}, tc.withCredentials(user, org))
+
+	return err
+}
+
 func (tc *TestClient) withCredentials(user, org *uuid.UUID) grpc.CallOption {
 	return grpc.PerRPCCredentials(&credentials{
-		key:  tc.key,
-		user: user,
-		org:  org,
+		key:    tc.key,
+		user:   user,
+		org:    org,
+		role:   tc.role,
+		apiKey: tc.apiKey,
 	})
 }
 
 type credentials struct {
-	key  []byte
-	user *uuid.UUID
-	org  *uuid.UUID
+	key    []byte
+	user   *uuid.UUID
+	org    *uuid.UUID
+	role   string
+	apiKey string
 }
 
 func (creds *credentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	if creds.apiKey != "" {
+		return map[string]string{
+			"x-api-key": creds.apiKey,
+		}, nil
+	}
+
+	claims := jwt.MapClaims{
 		"iss":  "testbert-authenticator",
+		"exp":  time.Now().Add(time.Hour).Unix(),
 		"user": creds.user.String(),
 		"org":  creds.org.String(),
-	}).SignedString(creds.key)
+	}
+	if creds.role != "" {
+		claims["role"] = creds.role
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(creds.key)
 	if err != nil {
 		return nil, err
 	}
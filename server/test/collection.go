@@ -7,6 +7,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func testCreateCollection(t *testing.T, tc *TestClient) {
@@ -261,6 +263,342 @@ func testDeleteCollection(t *testing.T, tc *TestClient) {
 	assert.Error(t, err)
 }
 
+func testListCollections(t *testing.T, tc *TestClient) {
+	userOne := uuid.New()
+	userTwo := uuid.New()
+	orgOne := uuid.New()
+	orgTwo := uuid.New()
+
+	private := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "private",
+	}, &userOne, &orgOne)
+
+	orgVisible := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "org visible",
+		OrgView:        true,
+	}, &userOne, &orgOne)
+
+	other := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "other",
+		OrgView:        true,
+	}, &userTwo, &orgOne)
+
+	tests := []struct {
+		name   string
+		user   *uuid.UUID
+		org    *uuid.UUID
+		filter collection.CollectionFilter
+		want   []string
+	}{
+		{
+			name:   "owner sees owned and org visible collections",
+			user:   &userOne,
+			org:    &orgOne,
+			filter: collection.CollectionFilter_COLLECTION_FILTER_ALL,
+			want:   []string{private.CollectionId, orgVisible.CollectionId, other.CollectionId},
+		},
+		{
+			name:   "owned filter only returns owned collections",
+			user:   &userOne,
+			org:    &orgOne,
+			filter: collection.CollectionFilter_COLLECTION_FILTER_OWNED,
+			want:   []string{private.CollectionId, orgVisible.CollectionId},
+		},
+		{
+			name:   "org shared filter only returns collections owned by others",
+			user:   &userOne,
+			org:    &orgOne,
+			filter: collection.CollectionFilter_COLLECTION_FILTER_ORG_SHARED,
+			want:   []string{other.CollectionId},
+		},
+		{
+			name:   "org member does not see private collections",
+			user:   &userTwo,
+			org:    &orgOne,
+			filter: collection.CollectionFilter_COLLECTION_FILTER_ALL,
+			want:   []string{orgVisible.CollectionId, other.CollectionId},
+		},
+		{
+			name:   "user from different org does not see org collections",
+			user:   &userTwo,
+			org:    &orgTwo,
+			filter: collection.CollectionFilter_COLLECTION_FILTER_ORG_SHARED,
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			token := ""
+			for {
+				out, err := tc.ListCollections(&collection.ListCollectionsRequest{
+					PageSize:  1,
+					PageToken: token,
+					Filter:    tt.filter,
+				}, tt.user, tt.org)
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.LessOrEqual(t, len(out.Collections), 1)
+				for _, c := range out.Collections {
+					got = append(got, c.CollectionId)
+				}
+				if out.NextPageToken == "" {
+					break
+				}
+				token = out.NextPageToken
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+
+	t.Run("invalid page token is rejected", func(t *testing.T) {
+		_, err := tc.ListCollections(&collection.ListCollectionsRequest{
+			PageToken: "not a token",
+		}, &userOne, &orgOne)
+		assert.Error(t, err)
+	})
+
+	t.Run("negative page size is rejected", func(t *testing.T) {
+		_, err := tc.ListCollections(&collection.ListCollectionsRequest{
+			PageSize: -1,
+		}, &userOne, &orgOne)
+		assert.Error(t, err)
+	})
+}
+
+func testCollectionMetadata(t *testing.T, tc *TestClient) {
+	userOne := uuid.New()
+	userTwo := uuid.New()
+	org := uuid.New()
+
+	created := mustCreateCollection(t, tc, &collection.Collection{
+		Title:          "metadata",
+		CollectionData: "four",
+		OrgView:        true,
+		OrgEdit:        true,
+	}, &userOne, &org)
+
+	assert.Equal(t, "metadata", created.Title)
+	assert.Equal(t, userOne.String(), created.OwnerId)
+	assert.Equal(t, userOne.String(), created.CreatedBy)
+	assert.Equal(t, userOne.String(), created.LastModifiedBy)
+	assert.Equal(t, int64(4), created.DataSize)
+	assert.NotNil(t, created.CreatedAt)
+	assert.NotNil(t, created.UpdatedAt)
+
+	updated, err := tc.UpdateCollection(&collection.Collection{
+		CollectionId:   created.CollectionId,
+		Title:          "metadata updated",
+		CollectionData: "four more",
+		OrgView:        true,
+		OrgEdit:        true,
+	}, &userTwo, &org)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "metadata updated", updated.Title)
+	assert.Equal(t, userOne.String(), updated.OwnerId)
+	assert.Equal(t, userOne.String(), updated.CreatedBy)
+	assert.Equal(t, userTwo.String(), updated.LastModifiedBy)
+	assert.Equal(t, int64(9), updated.DataSize)
+	assert.Equal(t, created.CreatedAt.AsTime(), updated.CreatedAt.AsTime())
+	assert.False(t, updated.UpdatedAt.AsTime().Before(created.UpdatedAt.AsTime()))
+}
+
+func testCollectionEtags(t *testing.T, tc *TestClient) {
+	userOne := uuid.New()
+	userTwo := uuid.New()
+	org := uuid.New()
+
+	created := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "one",
+		OrgView:        true,
+		OrgEdit:        true,
+	}, &userOne, &org)
+	assert.NotEmpty(t, created.Etag)
+
+	first, err := tc.UpdateCollection(&collection.Collection{
+		CollectionId:   created.CollectionId,
+		CollectionData: "first edit",
+		OrgView:        true,
+		OrgEdit:        true,
+		Etag:           created.Etag,
+	}, &userOne, &org)
+	if !assert.NoError(t, err, "update with current etag should succeed") {
+		return
+	}
+	assert.NotEqual(t, created.Etag, first.Etag)
+
+	_, err = tc.UpdateCollection(&collection.Collection{
+		CollectionId:   created.CollectionId,
+		CollectionData: "second edit",
+		OrgView:        true,
+		OrgEdit:        true,
+		Etag:           created.Etag,
+	}, &userTwo, &org)
+	assert.Equal(t, codes.Aborted, status.Code(err), "update with stale etag should be aborted")
+
+	err = tc.DeleteCollectionIfMatch(created.CollectionId, created.Etag, &userTwo, &org)
+	assert.Equal(t, codes.Aborted, status.Code(err), "delete with stale etag should be aborted")
+
+	current, err := tc.GetCollection(&userTwo, &org, created.CollectionId)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "first edit", current.CollectionData)
+	assert.Equal(t, first.Etag, current.Etag)
+
+	err = tc.DeleteCollectionIfMatch(created.CollectionId, current.Etag, &userTwo, &org)
+	assert.NoError(t, err, "delete with current etag should succeed")
+}
+
+func testPartialUpdateCollection(t *testing.T, tc *TestClient) {
+	user := uuid.New()
+	org := uuid.New()
+
+	created := mustCreateCollection(t, tc, &collection.Collection{
+		Title:          "partial",
+		CollectionData: "one",
+		OrgView:        true,
+	}, &user, &org)
+
+	tests := []struct {
+		name    string
+		paths   []string
+		input   *collection.Collection
+		want    *collection.Collection
+		wantErr bool
+	}{
+		{
+			name:  "only listed fields are updated",
+			paths: []string{"org_share"},
+			input: &collection.Collection{CollectionId: created.CollectionId, OrgShare: true},
+			want:  &collection.Collection{Title: "partial", CollectionData: "one", OrgView: true, OrgShare: true},
+		},
+		{
+			name:  "false can be set explicitly",
+			paths: []string{"org_view"},
+			input: &collection.Collection{CollectionId: created.CollectionId},
+			want:  &collection.Collection{Title: "partial", CollectionData: "one", OrgShare: true},
+		},
+		{
+			name:  "multiple fields are updated",
+			paths: []string{"title", "collection_data"},
+			input: &collection.Collection{CollectionId: created.CollectionId, Title: "renamed", CollectionData: "two"},
+			want:  &collection.Collection{Title: "renamed", CollectionData: "two", OrgShare: true},
+		},
+		{
+			name:    "unknown paths are rejected",
+			paths:   []string{"owner_id"},
+			input:   &collection.Collection{CollectionId: created.CollectionId},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tc.PatchCollection(tt.input, tt.paths, &user, &org)
+			if tt.wantErr {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want.Title, out.Title)
+			assert.Equal(t, tt.want.CollectionData, out.CollectionData)
+			assert.Equal(t, tt.want.OrgView, out.OrgView)
+			assert.Equal(t, tt.want.OrgEdit, out.OrgEdit)
+			assert.Equal(t, tt.want.OrgShare, out.OrgShare)
+		})
+	}
+}
+
+func testTrash(t *testing.T, tc *TestClient) {
+	userOne := uuid.New()
+	userTwo := uuid.New()
+	orgOne := uuid.New()
+	orgTwo := uuid.New()
+
+	one := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "one",
+		OrgView:        true,
+		OrgEdit:        true,
+		OrgShare:       true,
+	}, &userOne, &orgOne)
+
+	token := mustCreateShareToken(t, tc, one.CollectionId, &userOne, &orgOne)
+
+	err := tc.DeleteCollection(one.CollectionId, &userTwo, &orgOne)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("deleted collection is hidden", func(t *testing.T) {
+		_, err := tc.GetCollection(&userOne, &orgOne, one.CollectionId)
+		assert.Error(t, err)
+
+		_, err = tc.GetSharedCollection(token.Token)
+		assertTokenNotFound(t, err)
+
+		_, err = tc.UpdateCollection(&collection.Collection{CollectionId: one.CollectionId}, &userOne, &orgOne)
+		assert.Error(t, err)
+
+		err = tc.DeleteCollection(one.CollectionId, &userOne, &orgOne)
+		assert.Error(t, err)
+	})
+
+	t.Run("deleted collection is listed in the trash", func(t *testing.T) {
+		out, err := tc.ListTrash(&collection.ListTrashRequest{}, &userOne, &orgOne)
+		if !assert.NoError(t, err) || !assert.Len(t, out.Collections, 1) {
+			return
+		}
+		assert.Equal(t, one.CollectionId, out.Collections[0].CollectionId)
+		assert.Equal(t, userTwo.String(), out.Collections[0].DeletedBy)
+		assert.NotNil(t, out.Collections[0].DeletedAt)
+	})
+
+	t.Run("user from different org cannot see or restore the collection", func(t *testing.T) {
+		out, err := tc.ListTrash(&collection.ListTrashRequest{}, &userTwo, &orgTwo)
+		if assert.NoError(t, err) {
+			assert.Empty(t, out.Collections)
+		}
+
+		_, err = tc.RestoreCollection(one.CollectionId, &userTwo, &orgTwo)
+		assert.Error(t, err)
+	})
+
+	t.Run("org editor can restore the collection", func(t *testing.T) {
+		out, err := tc.RestoreCollection(one.CollectionId, &userTwo, &orgOne)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Nil(t, out.DeletedAt)
+		assert.NotEqual(t, one.Etag, out.Etag)
+
+		_, err = tc.UpdateCollection(&collection.Collection{
+			CollectionId:   one.CollectionId,
+			CollectionData: "stale",
+			Etag:           one.Etag,
+		}, &userOne, &orgOne)
+		assert.Equal(t, codes.Aborted, status.Code(err), "etags from before the trip through the trash are stale")
+
+		_, err = tc.GetCollection(&userOne, &orgOne, one.CollectionId)
+		assert.NoError(t, err)
+
+		_, err = tc.GetSharedCollection(token.Token)
+		assert.NoError(t, err, "share tokens survive a trip through the trash")
+	})
+
+	t.Run("live collections cannot be restored", func(t *testing.T) {
+		_, err := tc.RestoreCollection(one.CollectionId, &userOne, &orgOne)
+		assert.Error(t, err)
+	})
+}
+
 func mustCreateCollection(t *testing.T, tc *TestClient, in *collection.Collection, user, org *uuid.UUID) *collection.Collection {
 	out, err := tc.CreateCollection(in, user, org)
 	assert.NoError(t, err)
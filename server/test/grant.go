@@ -0,0 +1,151 @@
+package test
+
+import (
+	"testing"
+
+	"testbert/protobuf/collection"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testGrants(t *testing.T, tc *TestClient) {
+	owner := uuid.New()
+	viewer := uuid.New()
+	editor := uuid.New()
+	orgOne := uuid.New()
+	orgTwo := uuid.New()
+
+	c := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "granted",
+	}, &owner, &orgOne)
+
+	_, err := tc.GrantAccess(&collection.GrantAccessRequest{
+		CollectionId: c.CollectionId,
+		UserId:       viewer.String(),
+	}, &owner, &orgOne)
+	assert.NoError(t, err)
+
+	_, err = tc.GrantAccess(&collection.GrantAccessRequest{
+		CollectionId: c.CollectionId,
+		UserId:       editor.String(),
+		CanEdit:      true,
+		CanShare:     true,
+	}, &owner, &orgOne)
+	assert.NoError(t, err)
+
+	t.Run("grantee from another org can view", func(t *testing.T) {
+		out, err := tc.GetCollection(&viewer, &orgTwo, c.CollectionId)
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.CollectionData, out.CollectionData)
+		}
+	})
+
+	t.Run("grants only allow what they say", func(t *testing.T) {
+		_, err := tc.UpdateCollection(&collection.Collection{
+			CollectionId:   c.CollectionId,
+			CollectionData: "viewer edit",
+		}, &viewer, &orgTwo)
+		assert.Error(t, err)
+
+		_, err = tc.ShareCollection(c.CollectionId, &viewer, &orgTwo)
+		assert.Error(t, err)
+
+		_, err = tc.ListGrants(c.CollectionId, &viewer, &orgTwo)
+		assert.Error(t, err)
+
+		err = tc.DeleteCollection(c.CollectionId, &viewer, &orgTwo)
+		assert.Error(t, err)
+	})
+
+	t.Run("edit and share grants", func(t *testing.T) {
+		out, err := tc.UpdateCollection(&collection.Collection{
+			CollectionId:   c.CollectionId,
+			CollectionData: "editor edit",
+		}, &editor, &orgTwo)
+		if assert.NoError(t, err) {
+			assert.Equal(t, editor.String(), out.LastModifiedBy)
+		}
+
+		tok, err := tc.ShareCollection(c.CollectionId, &editor, &orgTwo)
+		if assert.NoError(t, err) {
+			assert.NoError(t, tc.DeleteShareToken(tok.Token, &editor, &orgTwo))
+		}
+
+		_, err = tc.ListGrants(c.CollectionId, &editor, &orgTwo)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), "only those who manage the collection see its grants")
+
+		grants, err := tc.ListGrants(c.CollectionId, &owner, &orgOne)
+		if assert.NoError(t, err) && assert.Len(t, grants.Grants, 2) {
+			assert.Equal(t, viewer.String(), grants.Grants[0].UserId)
+			assert.False(t, grants.Grants[0].CanEdit)
+			assert.Equal(t, editor.String(), grants.Grants[1].UserId)
+			assert.True(t, grants.Grants[1].CanShare)
+			assert.Equal(t, owner.String(), grants.Grants[1].GrantedBy)
+		}
+	})
+
+	t.Run("granted collections are listed", func(t *testing.T) {
+		out, err := tc.ListCollections(&collection.ListCollectionsRequest{
+			Filter: collection.CollectionFilter_COLLECTION_FILTER_GRANTED,
+		}, &viewer, &orgTwo)
+		if assert.NoError(t, err) && assert.Len(t, out.Collections, 1) {
+			assert.Equal(t, c.CollectionId, out.Collections[0].CollectionId)
+		}
+
+		out, err = tc.ListCollections(&collection.ListCollectionsRequest{}, &viewer, &orgTwo)
+		if assert.NoError(t, err) {
+			assert.Len(t, out.Collections, 1)
+		}
+
+		out, err = tc.ListCollections(&collection.ListCollectionsRequest{
+			Filter: collection.CollectionFilter_COLLECTION_FILTER_GRANTED,
+		}, &owner, &orgOne)
+		if assert.NoError(t, err) {
+			assert.Empty(t, out.Collections)
+		}
+	})
+
+	t.Run("only the owner can grant", func(t *testing.T) {
+		_, err := tc.GrantAccess(&collection.GrantAccessRequest{
+			CollectionId: c.CollectionId,
+			UserId:       uuid.NewString(),
+		}, &editor, &orgTwo)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = tc.GrantAccess(&collection.GrantAccessRequest{
+			CollectionId: c.CollectionId,
+			UserId:       owner.String(),
+		}, &owner, &orgOne)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		err = tc.RevokeAccess(c.CollectionId, &viewer, &editor, &orgTwo)
+		assert.Error(t, err)
+	})
+
+	t.Run("revoking removes access", func(t *testing.T) {
+		assert.NoError(t, tc.RevokeAccess(c.CollectionId, &viewer, &owner, &orgOne))
+
+		_, err := tc.GetCollection(&viewer, &orgTwo, c.CollectionId)
+		assert.Error(t, err)
+
+		err = tc.RevokeAccess(c.CollectionId, &viewer, &owner, &orgOne)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("grants are audited", func(t *testing.T) {
+		out, err := tc.GetAuditLog(&collection.GetAuditLogRequest{
+			CollectionId: c.CollectionId,
+			Actions:      []string{"grant", "revokeGrant"},
+		}, &owner, &orgOne)
+		if !assert.NoError(t, err) || !assert.Len(t, out.Events, 3) {
+			return
+		}
+		assert.Equal(t, "revokeGrant", out.Events[0].Action)
+		assert.Equal(t, viewer.String(), out.Events[0].GranteeId)
+		assert.Equal(t, editor.String(), out.Events[1].GranteeId)
+		assert.Equal(t, viewer.String(), out.Events[2].GranteeId)
+	})
+}
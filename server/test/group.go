@@ -0,0 +1,169 @@
+package test
+
+import (
+	"testing"
+
+	"testbert/protobuf/collection"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testGroups(t *testing.T, tc *TestClient) {
+	owner := uuid.New()
+	manager := uuid.New()
+	member := uuid.New()
+	outsider := uuid.New()
+	orgOne := uuid.New()
+	orgTwo := uuid.New()
+
+	group, err := tc.CreateGroup("editors", &manager, &orgOne)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	c := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "team collection",
+	}, &owner, &orgOne)
+
+	t.Run("group names are validated", func(t *testing.T) {
+		_, err := tc.CreateGroup("editors", &owner, &orgOne)
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+		_, err = tc.CreateGroup("  ", &owner, &orgOne)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = tc.CreateGroup("editors", &owner, &orgTwo)
+		assert.NoError(t, err, "names only need to be unique within an org")
+	})
+
+	t.Run("groups are scoped to the org", func(t *testing.T) {
+		out, err := tc.ListGroups(&owner, &orgOne)
+		if assert.NoError(t, err) && assert.Len(t, out.Groups, 1) {
+			assert.Equal(t, group.GroupId, out.Groups[0].GroupId)
+			assert.Equal(t, manager.String(), out.Groups[0].CreatedBy)
+		}
+
+		_, err = tc.ListGroupMembers(group.GroupId, &outsider, &orgTwo)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = tc.GrantAccess(&collection.GrantAccessRequest{
+			CollectionId: c.CollectionId,
+			GroupId:      group.GroupId,
+		}, &outsider, &orgTwo)
+		assert.Error(t, err)
+	})
+
+	t.Run("only the creator manages members", func(t *testing.T) {
+		_, err := tc.AddGroupMember(group.GroupId, &outsider, &owner, &orgOne)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = tc.AddGroupMember(group.GroupId, &member, &manager, &orgOne)
+		assert.NoError(t, err)
+
+		// adding again is a no-op
+		_, err = tc.AddGroupMember(group.GroupId, &member, &manager, &orgOne)
+		assert.NoError(t, err)
+
+		out, err := tc.ListGroupMembers(group.GroupId, &owner, &orgOne)
+		if assert.NoError(t, err) && assert.Len(t, out.Members, 1) {
+			assert.Equal(t, member.String(), out.Members[0].UserId)
+			assert.Equal(t, manager.String(), out.Members[0].AddedBy)
+		}
+	})
+
+	_, err = tc.GrantAccess(&collection.GrantAccessRequest{
+		CollectionId: c.CollectionId,
+		GroupId:      group.GroupId,
+		CanEdit:      true,
+	}, &owner, &orgOne)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("members have the group's access", func(t *testing.T) {
+		_, err := tc.GetCollection(&member, &orgTwo, c.CollectionId)
+		assert.NoError(t, err)
+
+		_, err = tc.UpdateCollection(&collection.Collection{
+			CollectionId:   c.CollectionId,
+			CollectionData: "edited by the team",
+		}, &member, &orgTwo)
+		assert.NoError(t, err)
+
+		_, err = tc.ShareCollection(c.CollectionId, &member, &orgTwo)
+		assert.Error(t, err)
+
+		_, err = tc.GetCollection(&outsider, &orgOne, c.CollectionId)
+		assert.Error(t, err)
+
+		out, err := tc.ListCollections(&collection.ListCollectionsRequest{
+			Filter: collection.CollectionFilter_COLLECTION_FILTER_GRANTED,
+		}, &member, &orgTwo)
+		if assert.NoError(t, err) {
+			assert.Len(t, out.Collections, 1)
+		}
+	})
+
+	t.Run("group grants are listed", func(t *testing.T) {
+		out, err := tc.ListGrants(c.CollectionId, &owner, &orgOne)
+		if assert.NoError(t, err) && assert.Len(t, out.Grants, 1) {
+			assert.Equal(t, group.GroupId, out.Grants[0].GroupId)
+			assert.Empty(t, out.Grants[0].UserId)
+			assert.True(t, out.Grants[0].CanEdit)
+		}
+	})
+
+	t.Run("removed members lose access", func(t *testing.T) {
+		assert.NoError(t, tc.RemoveGroupMember(group.GroupId, &member, &manager, &orgOne))
+
+		_, err := tc.GetCollection(&member, &orgTwo, c.CollectionId)
+		assert.Error(t, err)
+
+		err = tc.RemoveGroupMember(group.GroupId, &member, &manager, &orgOne)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("revoking a group grant is audited", func(t *testing.T) {
+		assert.NoError(t, tc.RevokeGroupAccess(c.CollectionId, group.GroupId, &owner, &orgOne))
+
+		err := tc.RevokeGroupAccess(c.CollectionId, group.GroupId, &owner, &orgOne)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		out, err := tc.GetAuditLog(&collection.GetAuditLogRequest{
+			CollectionId: c.CollectionId,
+			Actions:      []string{"grant", "revokeGrant"},
+		}, &owner, &orgOne)
+		if assert.NoError(t, err) && assert.Len(t, out.Events, 2) {
+			assert.Equal(t, "revokeGrant", out.Events[0].Action)
+			assert.Equal(t, group.GroupId, out.Events[0].GroupId)
+			assert.Empty(t, out.Events[0].GranteeId)
+		}
+	})
+
+	t.Run("deleting a group removes its grants", func(t *testing.T) {
+		_, err := tc.AddGroupMember(group.GroupId, &member, &manager, &orgOne)
+		assert.NoError(t, err)
+		_, err = tc.GrantAccess(&collection.GrantAccessRequest{
+			CollectionId: c.CollectionId,
+			GroupId:      group.GroupId,
+		}, &owner, &orgOne)
+		assert.NoError(t, err)
+
+		err = tc.DeleteGroup(group.GroupId, &owner, &orgOne)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.NoError(t, tc.DeleteGroup(group.GroupId, &manager, &orgOne))
+		err = tc.DeleteGroup(group.GroupId, &manager, &orgOne)
+		assert.Equal(t, codes.NotFound, status.Code(err), "already deleted")
+
+		_, err = tc.GetCollection(&member, &orgTwo, c.CollectionId)
+		assert.Error(t, err)
+
+		out, err := tc.ListGrants(c.CollectionId, &owner, &orgOne)
+		if assert.NoError(t, err) {
+			assert.Empty(t, out.Grants)
+		}
+	})
+}
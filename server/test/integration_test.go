@@ -63,6 +63,45 @@ func TestIntegration(t *testing.T) {
 		t.Run("DeleteCollection", func(t *testing.T) {
 			testDeleteCollection(t, tc)
 		})
+		t.Run("List Collections", func(t *testing.T) {
+			testListCollections(t, tc)
+		})
+		t.Run("Collection Metadata", func(t *testing.T) {
+			testCollectionMetadata(t, tc)
+		})
+		t.Run("Collection Etags", func(t *testing.T) {
+			testCollectionEtags(t, tc)
+		})
+		t.Run("Partial Update Collection", func(t *testing.T) {
+			testPartialUpdateCollection(t, tc)
+		})
+		t.Run("Revision History", func(t *testing.T) {
+			testRevisionHistory(t, tc)
+		})
+		t.Run("Trash", func(t *testing.T) {
+			testTrash(t, tc)
+		})
+		t.Run("Audit Log", func(t *testing.T) {
+			testAuditLog(t, tc)
+		})
+		t.Run("Grants", func(t *testing.T) {
+			testGrants(t, tc)
+		})
+		t.Run("Groups", func(t *testing.T) {
+			testGroups(t, tc)
+		})
+		t.Run("Org Admin", func(t *testing.T) {
+			testOrgAdmin(t, tc)
+		})
+		t.Run("Transfer Ownership", func(t *testing.T) {
+			testTransferOwnership(t, tc)
+		})
+		t.Run("API Keys", func(t *testing.T) {
+			testAPIKeys(t, tc)
+		})
+		t.Run("Authorization", func(t *testing.T) {
+			testAuthorization(t, tc)
+		})
 	})
 	t.Run("ShareToken Suite", func(t *testing.T) {
 		t.Run("Create ShareToken", func(t *testing.T) {
@@ -77,6 +116,15 @@ func TestIntegration(t *testing.T) {
 		t.Run("Delete ShareToken", func(t *testing.T) {
 			testDeleteSharedToken(t, tc)
 		})
+		t.Run("ShareToken Limits", func(t *testing.T) {
+			testShareTokenLimits(t, tc)
+		})
+		t.Run("List ShareTokens", func(t *testing.T) {
+			testListShareTokens(t, tc)
+		})
+		t.Run("Revoke ShareToken By ID", func(t *testing.T) {
+			testRevokeShareTokenByID(t, tc)
+		})
 	})
 }
 
@@ -0,0 +1,123 @@
+package test
+
+import (
+	"testing"
+
+	"testbert/protobuf/collection"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func testOrgAdmin(t *testing.T, tc *TestClient) {
+	leaver := uuid.New()
+	admin := uuid.New()
+	orgOne := uuid.New()
+	orgTwo := uuid.New()
+
+	admins := tc.AsOrgAdmin()
+
+	// a private collection nobody else in the org has access to
+	c := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "left behind",
+	}, &leaver, &orgOne)
+	tok := mustCreateShareToken(t, tc, c.CollectionId, &leaver, &orgOne)
+
+	t.Run("role is required", func(t *testing.T) {
+		_, err := tc.GetCollection(&admin, &orgOne, c.CollectionId)
+		assert.Error(t, err)
+	})
+
+	t.Run("admin of another org has no access", func(t *testing.T) {
+		_, err := admins.GetCollection(&admin, &orgTwo, c.CollectionId)
+		assert.Error(t, err)
+
+		out, err := admins.ListCollections(&collection.ListCollectionsRequest{}, &admin, &orgTwo)
+		if assert.NoError(t, err) {
+			assert.Empty(t, out.Collections)
+		}
+	})
+
+	t.Run("admin can view and edit", func(t *testing.T) {
+		_, err := admins.GetCollection(&admin, &orgOne, c.CollectionId)
+		assert.NoError(t, err)
+
+		out, err := admins.ListCollections(&collection.ListCollectionsRequest{}, &admin, &orgOne)
+		if assert.NoError(t, err) && assert.Len(t, out.Collections, 1) {
+			assert.Equal(t, c.CollectionId, out.Collections[0].CollectionId)
+		}
+
+		_, err = admins.UpdateCollection(&collection.Collection{
+			CollectionId:   c.CollectionId,
+			CollectionData: "taken over",
+		}, &admin, &orgOne)
+		assert.NoError(t, err)
+	})
+
+	t.Run("admin can share and revoke tokens", func(t *testing.T) {
+		out, err := admins.ListShareTokens(c.CollectionId, &admin, &orgOne)
+		if assert.NoError(t, err) {
+			assert.Len(t, out.Tokens, 1)
+		}
+
+		assert.NoError(t, admins.DeleteShareToken(tok.Token, &admin, &orgOne))
+
+		_, err = admins.ShareCollection(c.CollectionId, &admin, &orgOne)
+		assert.NoError(t, err)
+	})
+
+	t.Run("admin can manage grants", func(t *testing.T) {
+		_, err := admins.GrantAccess(&collection.GrantAccessRequest{
+			CollectionId: c.CollectionId,
+			UserId:       uuid.NewString(),
+		}, &admin, &orgOne)
+		assert.NoError(t, err)
+	})
+
+	t.Run("admin can delete and restore", func(t *testing.T) {
+		assert.NoError(t, admins.DeleteCollection(c.CollectionId, &admin, &orgOne))
+
+		trash, err := admins.ListTrash(&collection.ListTrashRequest{}, &admin, &orgOne)
+		if assert.NoError(t, err) {
+			assert.Len(t, trash.Collections, 1)
+		}
+
+		_, err = admins.RestoreCollection(c.CollectionId, &admin, &orgOne)
+		assert.NoError(t, err)
+	})
+
+	t.Run("admin actions are flagged", func(t *testing.T) {
+		out, err := admins.GetAuditLog(&collection.GetAuditLogRequest{CollectionId: c.CollectionId}, &admin, &orgOne)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		flagged := map[string]bool{}
+		for _, e := range out.Events {
+			if e.UserId == admin.String() {
+				assert.True(t, e.OrgAdmin, e.Action)
+				flagged[e.Action] = true
+			} else {
+				assert.False(t, e.OrgAdmin, e.Action)
+			}
+		}
+		for _, action := range []string{"read", "update", "revoke", "share", "grant", "delete", "undelete"} {
+			assert.True(t, flagged[action], action)
+		}
+	})
+
+	t.Run("admin can manage any group in the org", func(t *testing.T) {
+		group, err := tc.CreateGroup("leaver's team", &leaver, &orgOne)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		_, err = tc.AddGroupMember(group.GroupId, &admin, &admin, &orgOne)
+		assert.Error(t, err)
+
+		_, err = admins.AddGroupMember(group.GroupId, &admin, &admin, &orgOne)
+		assert.NoError(t, err)
+
+		assert.NoError(t, admins.DeleteGroup(group.GroupId, &admin, &orgOne))
+	})
+}
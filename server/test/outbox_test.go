@@ -0,0 +1,299 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"testbert/protobuf/collection"
+	"testbert/server/authz"
+	"testbert/server/config"
+	"testbert/server/model"
+	"testbert/server/server"
+	"testbert/server/sharetoken"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingPublisher Keeps every event it accepts, rejecting the first failures calls
+type recordingPublisher struct {
+	lock     sync.Mutex
+	failures int
+	events   []*model.AccessEvent
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e *model.AccessEvent) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("publisher unavailable")
+	}
+
+	p.events = append(p.events, e)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error {
+	return nil
+}
+
+func (p *recordingPublisher) received() []*model.AccessEvent {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return append([]*model.AccessEvent{}, p.events...)
+}
+
+func TestEventOutbox(t *testing.T) {
+	cfg := &config.Configuration{
+		AuthSecret:       "testkey",
+		ShareTokenSecret: "testsharekey",
+	}
+	hasher := sharetoken.NewHasher(cfg.ShareTokenSecret)
+
+	tests := []struct {
+		name     string
+		failures int
+	}{
+		{
+			name: "relays every change in order",
+		},
+		{
+			name:     "redelivers after publisher failures",
+			failures: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayed := &recordingPublisher{failures: tt.failures}
+			direct := &recordingPublisher{}
+
+			tc, store, closer := newMemServer(cfg, authz.Default{}, direct)
+			defer closer()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := server.StartEventRelay(ctx, store, relayed, 10*time.Millisecond)
+			defer func() {
+				cancel()
+				<-done
+			}()
+
+			user := uuid.New()
+			org := uuid.New()
+
+			c := mustCreateCollection(t, tc, &collection.Collection{
+				CollectionData: "outbox",
+			}, &user, &org)
+
+			_, err := tc.UpdateCollection(&collection.Collection{
+				CollectionId:   c.CollectionId,
+				CollectionData: "outbox updated",
+			}, &user, &org)
+			assert.NoError(t, err)
+
+			tok := mustCreateShareToken(t, tc, c.CollectionId, &user, &org)
+			_, err = tc.GetSharedCollection(tok.Token)
+			assert.NoError(t, err)
+			assert.NoError(t, tc.DeleteShareTokenByID(tok.TokenId, &user, &org))
+
+			_, err = tc.RestoreRevision(c.CollectionId, 1, &user, &org)
+			assert.NoError(t, err)
+			assert.NoError(t, tc.DeleteCollection(c.CollectionId, &user, &org))
+			_, err = tc.RestoreCollection(c.CollectionId, &user, &org)
+			assert.NoError(t, err)
+
+			_, err = tc.GetCollection(&user, &org, c.CollectionId)
+			assert.NoError(t, err)
+
+			want := []string{
+				model.ActionCreate,
+				model.ActionUpdate,
+				model.ActionShare,
+				model.ActionReadShared,
+				model.ActionRevoke,
+				model.ActionRestore,
+				model.ActionDelete,
+				model.ActionUndelete,
+			}
+			methods := map[string]string{
+				model.ActionCreate:     "CreateCollection",
+				model.ActionUpdate:     "UpdateCollection",
+				model.ActionShare:      "CreateShareToken",
+				model.ActionReadShared: "GetSharedCollection",
+				model.ActionRevoke:     "RevokeShareToken",
+				model.ActionRestore:    "RestoreCollectionRevision",
+				model.ActionDelete:     "DeleteCollection",
+				model.ActionUndelete:   "RestoreCollection",
+			}
+
+			var got []*model.AccessEvent
+			assert.Eventually(t, func() bool {
+				got = relayed.received()
+				return len(got) >= len(want)
+			}, 2*time.Second, 10*time.Millisecond)
+
+			if !assert.Len(t, got, len(want)) {
+				return
+			}
+
+			fingerprint := hasher.Fingerprint(tok.Token)
+			for i, e := range got {
+				assert.Equal(t, want[i], e.Action)
+				assert.Equal(t, c.CollectionId, e.CollectionID)
+				assert.NotZero(t, e.OccurredAt)
+				assert.Equal(t, "/collection.CollectionService/"+methods[e.Action], e.Method)
+				assert.NotEmpty(t, e.PeerAddress)
+				assert.Contains(t, e.UserAgent, "grpc-go")
+				if i > 0 {
+					assert.Greater(t, e.ID, got[i-1].ID)
+				}
+
+				switch e.Action {
+				case model.ActionReadShared:
+					assert.Nil(t, e.User)
+					assert.Equal(t, fingerprint, e.TokenFingerprint)
+				case model.ActionShare, model.ActionRevoke:
+					assert.Equal(t, &user, e.User)
+					assert.Equal(t, tok.TokenId, e.TokenID)
+					assert.Equal(t, fingerprint, e.TokenFingerprint)
+				default:
+					assert.Equal(t, &user, e.User)
+					assert.Equal(t, &org, e.OrgID)
+				}
+			}
+
+			// reads don't change anything so they skip the outbox
+			assert.Eventually(t, func() bool {
+				return len(direct.received()) == 1
+			}, time.Second, 10*time.Millisecond)
+			if events := direct.received(); assert.Len(t, events, 1) {
+				assert.Equal(t, model.ActionRead, events[0].Action)
+				assert.Equal(t, "/collection.CollectionService/GetCollection", events[0].Method)
+			}
+		})
+	}
+}
+
+func TestGroupEventOutbox(t *testing.T) {
+	cfg := &config.Configuration{
+		AuthSecret:       "testkey",
+		ShareTokenSecret: "testsharekey",
+	}
+
+	relayed := &recordingPublisher{}
+	tc, store, closer := newMemServer(cfg, authz.Default{}, &recordingPublisher{})
+	defer closer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := server.StartEventRelay(ctx, store, relayed, 10*time.Millisecond)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	manager := uuid.New()
+	member := uuid.New()
+	org := uuid.New()
+
+	group, err := tc.CreateGroup("outbox", &manager, &org)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = tc.AddGroupMember(group.GroupId, &member, &manager, &org)
+	assert.NoError(t, err)
+	_, err = tc.AddGroupMember(group.GroupId, &member, &manager, &org)
+	assert.NoError(t, err, "adding again changes nothing")
+	assert.NoError(t, tc.RemoveGroupMember(group.GroupId, &member, &manager, &org))
+	assert.NoError(t, tc.DeleteGroup(group.GroupId, &manager, &org))
+
+	want := []string{
+		model.ActionAddGroupMember,
+		model.ActionRemoveGroupMember,
+		model.ActionDeleteGroup,
+	}
+
+	var got []*model.AccessEvent
+	assert.Eventually(t, func() bool {
+		got = relayed.received()
+		return len(got) >= len(want)
+	}, 2*time.Second, 10*time.Millisecond)
+
+	if !assert.Len(t, got, len(want)) {
+		return
+	}
+
+	for i, e := range got {
+		assert.Equal(t, want[i], e.Action)
+		assert.Empty(t, e.CollectionID)
+		assert.Equal(t, group.GroupId, e.GroupID.String())
+		assert.Equal(t, &manager, e.User)
+		assert.Equal(t, &org, e.OrgID)
+		if e.Action == model.ActionDeleteGroup {
+			assert.Nil(t, e.GranteeID)
+		} else {
+			assert.Equal(t, &member, e.GranteeID)
+		}
+	}
+}
+
+func TestPurgeEventOutbox(t *testing.T) {
+	cfg := &config.Configuration{
+		AuthSecret:       "testkey",
+		ShareTokenSecret: "testsharekey",
+	}
+
+	relayed := &recordingPublisher{}
+	tc, store, closer := newMemServer(cfg, authz.Default{}, &recordingPublisher{})
+	defer closer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := server.StartEventRelay(ctx, store, relayed, 10*time.Millisecond)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	user := uuid.New()
+	org := uuid.New()
+
+	c := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "purged",
+	}, &user, &org)
+	assert.NoError(t, tc.DeleteCollection(c.CollectionId, &user, &org))
+
+	count, err := store.PurgeTrash(ctx, time.Now().Add(time.Minute))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(1), count)
+
+	want := []string{
+		model.ActionCreate,
+		model.ActionDelete,
+		model.ActionPurge,
+	}
+
+	var got []*model.AccessEvent
+	assert.Eventually(t, func() bool {
+		got = relayed.received()
+		return len(got) >= len(want)
+	}, 2*time.Second, 10*time.Millisecond)
+
+	if !assert.Len(t, got, len(want)) {
+		return
+	}
+
+	for i, e := range got {
+		assert.Equal(t, want[i], e.Action)
+		assert.Equal(t, c.CollectionId, e.CollectionID)
+		assert.Equal(t, &org, e.OrgID)
+	}
+	assert.Nil(t, got[2].User, "nobody asked for the purge")
+}
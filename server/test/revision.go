@@ -0,0 +1,115 @@
+package test
+
+import (
+	"testing"
+
+	"testbert/protobuf/collection"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRevisionHistory(t *testing.T, tc *TestClient) {
+	userOne := uuid.New()
+	userTwo := uuid.New()
+	orgOne := uuid.New()
+	orgTwo := uuid.New()
+
+	one := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "one",
+		OrgView:        true,
+		OrgEdit:        true,
+	}, &userOne, &orgOne)
+
+	_, err := tc.UpdateCollection(&collection.Collection{
+		CollectionId:   one.CollectionId,
+		CollectionData: "two",
+		OrgView:        true,
+		OrgEdit:        true,
+	}, &userTwo, &orgOne)
+	assert.NoError(t, err)
+
+	_, err = tc.UpdateCollection(&collection.Collection{
+		CollectionId:   one.CollectionId,
+		CollectionData: "three",
+		OrgView:        true,
+		OrgEdit:        true,
+	}, &userOne, &orgOne)
+	assert.NoError(t, err)
+
+	private := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "private",
+	}, &userOne, &orgOne)
+
+	t.Run("revisions are listed newest first across pages", func(t *testing.T) {
+		data := []string{}
+		token := ""
+		for {
+			out, err := tc.ListRevisions(&collection.ListRevisionsRequest{
+				CollectionId: one.CollectionId,
+				PageSize:     2,
+				PageToken:    token,
+			}, &userTwo, &orgOne)
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, r := range out.Revisions {
+				data = append(data, r.CollectionData)
+			}
+			if out.NextPageToken == "" {
+				break
+			}
+			token = out.NextPageToken
+		}
+		assert.Equal(t, []string{"three", "two", "one"}, data)
+	})
+
+	t.Run("revision records the editor", func(t *testing.T) {
+		out, err := tc.GetRevision(one.CollectionId, 2, &userOne, &orgOne)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "two", out.CollectionData)
+		assert.Equal(t, userTwo.String(), out.EditedBy)
+		assert.Equal(t, orgOne.String(), out.EditedByOrg)
+	})
+
+	t.Run("missing revision is not found", func(t *testing.T) {
+		_, err := tc.GetRevision(one.CollectionId, 42, &userOne, &orgOne)
+		assert.Error(t, err)
+	})
+
+	t.Run("other user cannot list revisions of private collection", func(t *testing.T) {
+		_, err := tc.ListRevisions(&collection.ListRevisionsRequest{CollectionId: private.CollectionId}, &userTwo, &orgOne)
+		assert.Error(t, err)
+	})
+
+	t.Run("user from different org cannot read revisions", func(t *testing.T) {
+		_, err := tc.GetRevision(one.CollectionId, 1, &userTwo, &orgTwo)
+		assert.Error(t, err)
+	})
+
+	t.Run("user from different org cannot restore revisions", func(t *testing.T) {
+		_, err := tc.RestoreRevision(one.CollectionId, 1, &userTwo, &orgTwo)
+		assert.Error(t, err)
+	})
+
+	t.Run("org editor can restore a revision", func(t *testing.T) {
+		out, err := tc.RestoreRevision(one.CollectionId, 1, &userTwo, &orgOne)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "one", out.CollectionData)
+
+		latest, err := tc.ListRevisions(&collection.ListRevisionsRequest{
+			CollectionId: one.CollectionId,
+			PageSize:     1,
+		}, &userOne, &orgOne)
+		if !assert.NoError(t, err) || !assert.Len(t, latest.Revisions, 1) {
+			return
+		}
+		assert.Equal(t, int64(4), latest.Revisions[0].Revision)
+		assert.Equal(t, "one", latest.Revisions[0].CollectionData)
+		assert.Equal(t, userTwo.String(), latest.Revisions[0].EditedBy)
+	})
+}
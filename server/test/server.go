@@ -6,10 +6,15 @@ import (
 	"net"
 
 	"testbert/protobuf/collection"
+	"testbert/server/authz"
 	"testbert/server/config"
+	"testbert/server/datastore"
+	"testbert/server/datastore/memstore"
 	"testbert/server/datastore/sqlstore"
+	"testbert/server/events"
 	"testbert/server/interceptors/auth"
 	"testbert/server/server"
+	"testbert/server/sharetoken"
 
 	"github.com/jmoiron/sqlx"
 	"google.golang.org/grpc"
@@ -21,10 +26,21 @@ func newServer(db *sqlx.DB, cfg *config.Configuration) (*TestClient, func()) {
 	lis := bufconn.Listen(101024 * 1024)
 
 	cfg.AuthSecret = "testkey"
+	cfg.ShareTokenSecret = "testsharekey"
+	cfg.APIKeySecret = "testapikey"
+	hasher := sharetoken.NewHasher(cfg.ShareTokenSecret)
+	store := sqlstore.NewSQLStore(db, hasher, sharetoken.NewHasher(cfg.APIKeySecret))
 
-	grpcSrv := grpc.NewServer(grpc.UnaryInterceptor(auth.Interceptor(cfg)))
+	public := auth.NewPublicMethods()
+	grpcSrv := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.Interceptor(cfg, auth.Secret(cfg.AuthSecret), public, store)),
+		grpc.StreamInterceptor(auth.StreamInterceptor(cfg, auth.Secret(cfg.AuthSecret), public, store)),
+	)
 
-	collection.RegisterCollectionServiceServer(grpcSrv, server.NewCollectionServer(sqlstore.NewSQLStore(db), "testkey"))
+	collection.RegisterCollectionServiceServer(grpcSrv, server.NewCollectionServer(store, authz.Default{}, "testkey", hasher, newDispatcher(events.NopPublisher{})))
+	if err := public.Load(grpcSrv); err != nil {
+		log.Fatal(err)
+	}
 	go func() {
 		if err := grpcSrv.Serve(lis); err != nil {
 			log.Fatal(err)
@@ -50,3 +66,54 @@ func newServer(db *sqlx.DB, cfg *config.Configuration) (*TestClient, func()) {
 
 	return client, closer
 }
+
+// newMemServer serves an in memory datastore authorized by policy, returning the store so tests can inspect it
+// directly
+func newMemServer(cfg *config.Configuration, policy authz.Policy, publisher events.EventPublisher) (*TestClient, datastore.TestBertDatastore, func()) {
+	lis := bufconn.Listen(1024 * 1024)
+
+	hasher := sharetoken.NewHasher(cfg.ShareTokenSecret)
+	store := memstore.NewMemStore(hasher, sharetoken.NewHasher(cfg.APIKeySecret))
+
+	public := auth.NewPublicMethods()
+	grpcSrv := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.Interceptor(cfg, auth.Secret(cfg.AuthSecret), public, store)),
+		grpc.StreamInterceptor(auth.StreamInterceptor(cfg, auth.Secret(cfg.AuthSecret), public, store)),
+	)
+
+	collection.RegisterCollectionServiceServer(grpcSrv, server.NewCollectionServer(store, policy, cfg.AuthSecret, hasher, newDispatcher(publisher)))
+	if err := public.Load(grpcSrv); err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		_ = grpcSrv.Serve(lis)
+	}()
+
+	conn, err := grpc.NewClient("passthrough://bufnet", grpc.WithContextDialer(func(_ context.Context, _ string) (net.Conn, error) {
+		return lis.Dial()
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	closer := func() {
+		_ = conn.Close()
+		grpcSrv.Stop()
+	}
+
+	client := NewClient(collection.NewCollectionServiceClient(conn), cfg.AuthSecret)
+
+	return client, store, closer
+}
+
+func newDispatcher(publisher events.EventPublisher) *events.Dispatcher {
+	dispatcher, err := events.NewDispatcher(publisher, events.DispatcherOptions{
+		Policy: events.PolicyBlock,
+		Size:   1000,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return dispatcher
+}
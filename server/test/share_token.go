@@ -2,11 +2,15 @@ package test
 
 import (
 	"testing"
+	"time"
 
 	"testbert/protobuf/collection"
+	"testbert/server/tberrors"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func testCreateShareToken(t *testing.T, tc *TestClient) {
@@ -220,8 +224,163 @@ func testDeleteSharedToken(t *testing.T, tc *TestClient) {
 	}
 }
 
+func testShareTokenLimits(t *testing.T, tc *TestClient) {
+	user := uuid.New()
+	org := uuid.New()
+
+	one := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "one",
+	}, &user, &org)
+
+	t.Run("token stops working after max uses", func(t *testing.T) {
+		token, err := tc.ShareCollectionWithLimits(one.CollectionId, nil, 2, &user, &org)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, int64(2), token.MaxUses)
+
+		for range 2 {
+			_, err := tc.GetSharedCollection(token.Token)
+			assert.NoError(t, err)
+		}
+
+		_, err = tc.GetSharedCollection(token.Token)
+		assertTokenNotFound(t, err)
+	})
+
+	t.Run("token stops working after it expires", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Second)
+		token, err := tc.ShareCollectionWithLimits(one.CollectionId, &expiresAt, 0, &user, &org)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, expiresAt.Unix(), token.ExpiresAt.AsTime().Unix())
+
+		_, err = tc.GetSharedCollection(token.Token)
+		assert.NoError(t, err)
+
+		time.Sleep(time.Until(expiresAt) + 100*time.Millisecond)
+
+		_, err = tc.GetSharedCollection(token.Token)
+		assertTokenNotFound(t, err)
+	})
+
+	t.Run("expiry in the past is rejected", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Minute)
+		_, err := tc.ShareCollectionWithLimits(one.CollectionId, &expiresAt, 0, &user, &org)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("negative max uses is rejected", func(t *testing.T) {
+		_, err := tc.ShareCollectionWithLimits(one.CollectionId, nil, -1, &user, &org)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func testListShareTokens(t *testing.T, tc *TestClient) {
+	userOne := uuid.New()
+	userTwo := uuid.New()
+	orgOne := uuid.New()
+	orgTwo := uuid.New()
+
+	one := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "one",
+	}, &userOne, &orgOne)
+
+	two := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "two",
+		OrgView:        true,
+		OrgShare:       true,
+	}, &userOne, &orgOne)
+
+	used := mustCreateShareToken(t, tc, two.CollectionId, &userOne, &orgOne)
+	unused := mustCreateShareToken(t, tc, two.CollectionId, &userTwo, &orgOne)
+	mustCreateShareToken(t, tc, one.CollectionId, &userOne, &orgOne)
+
+	for range 3 {
+		_, err := tc.GetSharedCollection(used.Token)
+		assert.NoError(t, err)
+	}
+
+	t.Run("org member can list tokens with usage", func(t *testing.T) {
+		out, err := tc.ListShareTokens(two.CollectionId, &userTwo, &orgOne)
+		if !assert.NoError(t, err) || !assert.Len(t, out.Tokens, 2) {
+			return
+		}
+
+		byID := map[string]*collection.ShareToken{}
+		for _, tok := range out.Tokens {
+			assert.Empty(t, tok.Token)
+			byID[tok.TokenId] = tok
+		}
+
+		if assert.Contains(t, byID, used.TokenId) {
+			assert.Equal(t, int64(3), byID[used.TokenId].UseCount)
+			assert.Equal(t, userOne.String(), byID[used.TokenId].CreatedBy)
+			assert.NotNil(t, byID[used.TokenId].CreatedAt)
+			assert.NotNil(t, byID[used.TokenId].LastAccessedAt)
+		}
+		if assert.Contains(t, byID, unused.TokenId) {
+			assert.Equal(t, int64(0), byID[unused.TokenId].UseCount)
+			assert.Equal(t, userTwo.String(), byID[unused.TokenId].CreatedBy)
+			assert.Nil(t, byID[unused.TokenId].LastAccessedAt)
+		}
+	})
+
+	t.Run("other user cannot list tokens on private collection", func(t *testing.T) {
+		_, err := tc.ListShareTokens(one.CollectionId, &userTwo, &orgOne)
+		assert.Error(t, err)
+	})
+
+	t.Run("user from different org cannot list tokens", func(t *testing.T) {
+		_, err := tc.ListShareTokens(two.CollectionId, &userTwo, &orgTwo)
+		assert.Error(t, err)
+	})
+}
+
+func testRevokeShareTokenByID(t *testing.T, tc *TestClient) {
+	userOne := uuid.New()
+	userTwo := uuid.New()
+	orgOne := uuid.New()
+
+	c := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "revoke by id",
+	}, &userOne, &orgOne)
+
+	tok := mustCreateShareToken(t, tc, c.CollectionId, &userOne, &orgOne)
+	assert.NotEmpty(t, tok.TokenId)
+	assert.NotEqual(t, tok.Token, tok.TokenId)
+
+	t.Run("other user cannot revoke by id", func(t *testing.T) {
+		err := tc.DeleteShareTokenByID(tok.TokenId, &userTwo, &orgOne)
+		assert.Error(t, err)
+
+		_, err = tc.GetSharedCollection(tok.Token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown id", func(t *testing.T) {
+		err := tc.DeleteShareTokenByID("0000000000000000", &userOne, &orgOne)
+		assert.Error(t, err)
+	})
+
+	t.Run("owner can revoke by id", func(t *testing.T) {
+		err := tc.DeleteShareTokenByID(tok.TokenId, &userOne, &orgOne)
+		assert.NoError(t, err)
+
+		_, err = tc.GetSharedCollection(tok.Token)
+		assert.Error(t, err)
+	})
+}
+
 func mustCreateShareToken(t *testing.T, tc *TestClient, id string, user, org *uuid.UUID) *collection.ShareToken {
 	out, err := tc.ShareCollection(id, user, org)
 	assert.NoError(t, err)
 	return out
 }
+
+// assertTokenNotFound checks err is the error every store returns for a token that can't be used
+func assertTokenNotFound(t *testing.T, err error) {
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, status.Convert(tberrors.ErrTokenNotFound).Message(), status.Convert(err).Message())
+}
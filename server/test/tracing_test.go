@@ -0,0 +1,108 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"testbert/protobuf/collection"
+	"testbert/server/authz"
+	"testbert/server/config"
+	"testbert/server/redact"
+	"testbert/server/redact/redacttest"
+	"testbert/server/sharetoken"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	memory         = tracetest.NewInMemoryExporter()
+	installTracing sync.Once
+)
+
+func TestTraceRedaction(t *testing.T) {
+	cfg := &config.Configuration{
+		AuthSecret:       "testkey",
+		ShareTokenSecret: "testsharekey",
+		TraceRedactKeys:  []string{"share.token"},
+	}
+	hasher := sharetoken.NewHasher(cfg.ShareTokenSecret)
+
+	// the server's tracer only binds to the first global provider, so it is installed once
+	installTracing.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(
+			sdktrace.WithSyncer(redact.NewExporter(memory, redact.NewRedactor(cfg.TraceRedactKeys, hasher.Fingerprint))),
+		))
+	})
+	memory.Reset()
+
+	direct := &recordingPublisher{}
+	tc, _, closer := newMemServer(cfg, authz.Default{}, direct)
+	defer closer()
+
+	user := uuid.New()
+	org := uuid.New()
+
+	c := mustCreateCollection(t, tc, &collection.Collection{
+		CollectionData: "traced secret data",
+	}, &user, &org)
+
+	tok := mustCreateShareToken(t, tc, c.CollectionId, &user, &org)
+	if !assert.NotEmpty(t, tok.Token) {
+		return
+	}
+
+	_, err := tc.GetSharedCollection(tok.Token)
+	assert.NoError(t, err)
+	assert.NoError(t, tc.DeleteShareToken(tok.Token, &user, &org))
+	_, err = tc.GetSharedCollection(tok.Token)
+	assert.Error(t, err)
+
+	_, err = tc.GetCollection(&user, &org, c.CollectionId)
+	assert.NoError(t, err)
+
+	// the server only records fingerprints itself, the exporter catches anything else that records the raw token
+	_, leaky := otel.Tracer("testbert/test").Start(context.Background(), "Leaky")
+	leaky.SetAttributes(attribute.String("share.token", tok.Token))
+	leaky.End()
+
+	spans := memory.GetSpans()
+	assert.NotEmpty(t, spans)
+
+	// events identify the span of the request that caused them
+	assert.Eventually(t, func() bool {
+		return len(direct.received()) == 1
+	}, time.Second, 10*time.Millisecond)
+	if received := direct.received(); assert.Len(t, received, 1) {
+		found := false
+		for _, span := range spans {
+			if span.Name == "GetCollection" {
+				found = true
+				assert.Equal(t, span.SpanContext.TraceID().String(), received[0].TraceID)
+				assert.Equal(t, span.SpanContext.SpanID().String(), received[0].SpanID)
+			}
+		}
+		assert.True(t, found)
+	}
+
+	fingerprint := hasher.Fingerprint(tok.Token)
+	fingerprinted := 0
+	for _, span := range spans {
+		exported := redacttest.Dump(span)
+		assert.NotContains(t, exported, tok.Token, "span %s leaks the share token", span.Name)
+		assert.NotContains(t, exported, "traced secret data", "span %s leaks collection data", span.Name)
+		if strings.Contains(exported, fingerprint) {
+			fingerprinted++
+		}
+	}
+
+	// create, two reads, revoke and the leaky span all identify the token by fingerprint
+	assert.Equal(t, 5, fingerprinted)
+}
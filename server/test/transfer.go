@@ -0,0 +1,152 @@
+package test
+
+import (
+	"testing"
+
+	"testbert/protobuf/collection"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testTransferOwnership(t *testing.T, tc *TestClient) {
+	owner := uuid.New()
+	heir := uuid.New()
+	outsider := uuid.New()
+	admin := uuid.New()
+	orgOne := uuid.New()
+	orgTwo := uuid.New()
+
+	t.Run("within the org is immediate", func(t *testing.T) {
+		c := mustCreateCollection(t, tc, &collection.Collection{
+			CollectionData: "handed over",
+			OrgView:        true,
+		}, &owner, &orgOne)
+		tok := mustCreateShareToken(t, tc, c.CollectionId, &owner, &orgOne)
+
+		out, err := tc.TransferOwnership(c.CollectionId, &heir, nil, &owner, &orgOne)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.False(t, out.Pending)
+		assert.Equal(t, heir.String(), out.Collection.OwnerId)
+		assert.True(t, out.Collection.OrgView)
+
+		_, err = tc.GetSharedCollection(tok.Token)
+		assert.Error(t, err, "the old owner's tokens are revoked")
+
+		// the old owner is now an ordinary member of the org
+		_, err = tc.GetCollection(&owner, &orgOne, c.CollectionId)
+		assert.NoError(t, err)
+		_, err = tc.TransferOwnership(c.CollectionId, &owner, nil, &owner, &orgOne)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		log, err := tc.GetAuditLog(&collection.GetAuditLogRequest{CollectionId: c.CollectionId}, &heir, &orgOne)
+		if !assert.NoError(t, err) {
+			return
+		}
+		found := false
+		for _, e := range log.Events {
+			if e.Action == "transfer" {
+				found = true
+				assert.Equal(t, owner.String(), e.UserId)
+				assert.Equal(t, heir.String(), e.GranteeId)
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("stays in the collection's org when the owner is logged in to another", func(t *testing.T) {
+		c := mustCreateCollection(t, tc, &collection.Collection{
+			CollectionData: "same org",
+			OrgView:        true,
+		}, &owner, &orgOne)
+
+		out, err := tc.TransferOwnership(c.CollectionId, &heir, nil, &owner, &orgTwo)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.False(t, out.Pending)
+		assert.Equal(t, heir.String(), out.Collection.OwnerId)
+		assert.True(t, out.Collection.OrgView)
+
+		// still visible to the org it was in
+		_, err = tc.GetCollection(&outsider, &orgOne, c.CollectionId)
+		assert.NoError(t, err)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		c := mustCreateCollection(t, tc, &collection.Collection{CollectionData: "mine"}, &owner, &orgOne)
+
+		_, err := tc.TransferOwnership(c.CollectionId, &owner, nil, &owner, &orgOne)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = tc.TransferOwnership(c.CollectionId, &heir, nil, &outsider, &orgOne)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = tc.TransferOwnership(uuid.NewString(), &heir, nil, &owner, &orgOne)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("org admin can transfer", func(t *testing.T) {
+		c := mustCreateCollection(t, tc, &collection.Collection{CollectionData: "left behind"}, &owner, &orgOne)
+
+		out, err := tc.AsOrgAdmin().TransferOwnership(c.CollectionId, &heir, nil, &admin, &orgOne)
+		if assert.NoError(t, err) {
+			assert.Equal(t, heir.String(), out.Collection.OwnerId)
+		}
+	})
+
+	t.Run("to another org needs consent", func(t *testing.T) {
+		c := mustCreateCollection(t, tc, &collection.Collection{
+			CollectionData: "moving on",
+			OrgView:        true,
+			OrgEdit:        true,
+		}, &owner, &orgOne)
+		tok := mustCreateShareToken(t, tc, c.CollectionId, &owner, &orgOne)
+
+		out, err := tc.TransferOwnership(c.CollectionId, &heir, &orgTwo, &owner, &orgOne)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, out.Pending)
+		assert.Nil(t, out.Collection)
+
+		log, err := tc.GetAuditLog(&collection.GetAuditLogRequest{CollectionId: c.CollectionId, Actions: []string{"transferOffered"}}, &owner, &orgOne)
+		if assert.NoError(t, err) && assert.Len(t, log.Events, 1) {
+			assert.Equal(t, owner.String(), log.Events[0].UserId)
+			assert.Equal(t, heir.String(), log.Events[0].GranteeId)
+		}
+
+		// nothing changes until the offer is accepted
+		got, err := tc.GetCollection(&owner, &orgOne, c.CollectionId)
+		if assert.NoError(t, err) {
+			assert.Equal(t, owner.String(), got.OwnerId)
+		}
+		_, err = tc.GetSharedCollection(tok.Token)
+		assert.NoError(t, err)
+
+		_, err = tc.AcceptTransfer(c.CollectionId, &outsider, &orgTwo)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		_, err = tc.AcceptTransfer(c.CollectionId, &heir, &orgOne)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		accepted, err := tc.AcceptTransfer(c.CollectionId, &heir, &orgTwo)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, heir.String(), accepted.OwnerId)
+		assert.False(t, accepted.OrgView)
+		assert.False(t, accepted.OrgEdit)
+
+		_, err = tc.GetSharedCollection(tok.Token)
+		assert.Error(t, err)
+		_, err = tc.GetCollection(&owner, &orgOne, c.CollectionId)
+		assert.Error(t, err, "the old org loses access")
+
+		_, err = tc.AcceptTransfer(c.CollectionId, &heir, &orgTwo)
+		assert.Equal(t, codes.NotFound, status.Code(err), "offers can only be accepted once")
+	})
+}